@@ -0,0 +1,221 @@
+package dynamock
+
+import (
+	"bytes"
+
+	"foxygo.at/s/errs"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// attrEqual compares two attribute values by value. Numbers are compared
+// numerically and sets irrespective of the order of their members.
+func attrEqual(a, b *dynamodb.AttributeValue) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	switch {
+	case a.S != nil:
+		return b.S != nil && *a.S == *b.S
+	case a.N != nil:
+		if b.N == nil {
+			return false
+		}
+		c, ok := cmpNumberStrings(*a.N, *b.N)
+		return ok && c == 0
+	case a.B != nil:
+		return b.B != nil && bytes.Equal(a.B, b.B)
+	case a.BOOL != nil:
+		return b.BOOL != nil && *a.BOOL == *b.BOOL
+	case a.NULL != nil:
+		return b.NULL != nil
+	case a.L != nil:
+		return b.L != nil && listEqual(a.L, b.L)
+	case a.M != nil:
+		return b.M != nil && mapEqual(a.M, b.M)
+	}
+	return setEqual(a, b)
+}
+
+func listEqual(l1, l2 []*dynamodb.AttributeValue) bool {
+	if len(l1) != len(l2) {
+		return false
+	}
+	for i := range l1 {
+		if !attrEqual(l1[i], l2[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func mapEqual(m1, m2 Item) bool {
+	if len(m1) != len(m2) {
+		return false
+	}
+	for k, v := range m1 {
+		if !attrEqual(v, m2[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+func setEqual(a, b *dynamodb.AttributeValue) bool {
+	typeA, keysA := setKeys(a)
+	typeB, keysB := setKeys(b)
+	if typeA == "" || typeA != typeB {
+		return false
+	}
+	setA := map[string]bool{}
+	for _, k := range keysA {
+		setA[k] = true
+	}
+	setB := map[string]bool{}
+	for _, k := range keysB {
+		if !setA[k] {
+			return false
+		}
+		setB[k] = true
+	}
+	return len(setA) == len(setB)
+}
+
+// setKeys returns the set type "SS", "NS" or "BS" of attr and its members
+// in canonical string form for comparison. The set type is empty if attr
+// is not a set.
+func setKeys(attr *dynamodb.AttributeValue) (string, []string) {
+	switch {
+	case attr.SS != nil:
+		keys := make([]string, len(attr.SS))
+		for i, s := range attr.SS {
+			keys[i] = *s
+		}
+		return "SS", keys
+	case attr.NS != nil:
+		keys := make([]string, len(attr.NS))
+		for i, s := range attr.NS {
+			n, _ := ParseNumber(*s)
+			keys[i] = n.String()
+		}
+		return "NS", keys
+	case attr.BS != nil:
+		keys := make([]string, len(attr.BS))
+		for i, b := range attr.BS {
+			keys[i] = string(b)
+		}
+		return "BS", keys
+	}
+	return "", nil
+}
+
+func appendSetMember(dst, src *dynamodb.AttributeValue, i int) {
+	switch {
+	case src.SS != nil:
+		dst.SS = append(dst.SS, src.SS[i])
+	case src.NS != nil:
+		dst.NS = append(dst.NS, src.NS[i])
+	case src.BS != nil:
+		dst.BS = append(dst.BS, src.BS[i])
+	}
+}
+
+// setContains reports whether val is a member of set attr.
+func setContains(attr, val *dynamodb.AttributeValue) bool {
+	setType, keys := setKeys(attr)
+	var key string
+	switch {
+	case setType == "SS" && val.S != nil:
+		key = *val.S
+	case setType == "NS" && val.N != nil:
+		n, err := ParseNumber(*val.N)
+		if err != nil {
+			return false
+		}
+		key = n.String()
+	case setType == "BS" && val.B != nil:
+		key = string(val.B)
+	default:
+		return false
+	}
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// addAttr implements the update expression action ADD for numbers and sets.
+// cur is the current value of the attribute and may be nil.
+func addAttr(cur, val *dynamodb.AttributeValue) (*dynamodb.AttributeValue, error) {
+	if cur == nil {
+		if valType, _ := setKeys(val); val.N == nil && valType == "" {
+			return nil, errs.Errorf("%v: ADD: %v: operand must be a number or a set", ErrInvalidUpdateExpression, ErrInvalidType)
+		}
+		return val, nil
+	}
+	if val.N != nil && cur.N != nil {
+		n1, err := ParseNumber(*cur.N)
+		if err != nil {
+			return nil, err
+		}
+		n2, err := ParseNumber(*val.N)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := n1.Add(n2)
+		if err != nil {
+			return nil, err
+		}
+		s := sum.String()
+		return &dynamodb.AttributeValue{N: &s}, nil
+	}
+	curType, curKeys := setKeys(cur)
+	valType, valKeys := setKeys(val)
+	if curType == "" || curType != valType {
+		return nil, errs.Errorf("%v: ADD: %v: operand types must match numbers or sets", ErrInvalidUpdateExpression, ErrInvalidType)
+	}
+	result := &dynamodb.AttributeValue{}
+	seen := map[string]bool{}
+	for i, k := range curKeys {
+		appendSetMember(result, cur, i)
+		seen[k] = true
+	}
+	for i, k := range valKeys {
+		if !seen[k] {
+			appendSetMember(result, val, i)
+			seen[k] = true
+		}
+	}
+	return result, nil
+}
+
+// deleteAttr implements the update expression action DELETE, removing the
+// members of set val from set cur. The result is nil if no members remain.
+func deleteAttr(cur, val *dynamodb.AttributeValue) (*dynamodb.AttributeValue, error) {
+	valType, valKeys := setKeys(val)
+	if valType == "" {
+		return nil, errs.Errorf("%v: DELETE: %v: operand must be a set", ErrInvalidUpdateExpression, ErrInvalidType)
+	}
+	if cur == nil {
+		return nil, nil
+	}
+	curType, curKeys := setKeys(cur)
+	if curType != valType {
+		return nil, errs.Errorf("%v: DELETE: %v: operand types must match", ErrInvalidUpdateExpression, ErrInvalidType)
+	}
+	del := map[string]bool{}
+	for _, k := range valKeys {
+		del[k] = true
+	}
+	result := &dynamodb.AttributeValue{}
+	for i, k := range curKeys {
+		if !del[k] {
+			appendSetMember(result, cur, i)
+		}
+	}
+	if countTypes(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
@@ -0,0 +1,143 @@
+package dynamock
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func strSet(s ...string) *dynamodb.AttributeValue {
+	set := make([]*string, len(s))
+	for i := range s {
+		set[i] = &s[i]
+	}
+	return &dynamodb.AttributeValue{SS: set}
+}
+
+func numSet(s ...string) *dynamodb.AttributeValue {
+	set := make([]*string, len(s))
+	for i := range s {
+		set[i] = &s[i]
+	}
+	return &dynamodb.AttributeValue{NS: set}
+}
+
+func TestAttrEqual(t *testing.T) {
+	testCases := map[string]struct {
+		a, b *dynamodb.AttributeValue
+		want bool
+	}{
+		"nil":           {a: nil, b: nil, want: true},
+		"nil_S":         {a: nil, b: &dynamodb.AttributeValue{S: strPtr("a")}, want: false},
+		"S":             {a: &dynamodb.AttributeValue{S: strPtr("a")}, b: &dynamodb.AttributeValue{S: strPtr("a")}, want: true},
+		"S_N":           {a: &dynamodb.AttributeValue{S: strPtr("1")}, b: &dynamodb.AttributeValue{N: strPtr("1")}, want: false},
+		"N":             {a: &dynamodb.AttributeValue{N: strPtr("1")}, b: &dynamodb.AttributeValue{N: strPtr("1.0")}, want: true},
+		"N_S":           {a: &dynamodb.AttributeValue{N: strPtr("1")}, b: &dynamodb.AttributeValue{S: strPtr("1")}, want: false},
+		"B":             {a: &dynamodb.AttributeValue{B: []byte{1}}, b: &dynamodb.AttributeValue{B: []byte{1}}, want: true},
+		"BOOL":          {a: &dynamodb.AttributeValue{BOOL: boolPtr(true)}, b: &dynamodb.AttributeValue{BOOL: boolPtr(false)}, want: false},
+		"NULL":          {a: &dynamodb.AttributeValue{NULL: boolPtr(true)}, b: &dynamodb.AttributeValue{NULL: boolPtr(true)}, want: true},
+		"SS_order":      {a: strSet("a", "b"), b: strSet("b", "a"), want: true},
+		"SS_diff":       {a: strSet("a", "b"), b: strSet("a", "c"), want: false},
+		"SS_len":        {a: strSet("a", "b"), b: strSet("a"), want: false},
+		"NS_canonical":  {a: numSet("1", "2.50"), b: numSet("2.5", "1.0"), want: true},
+		"SS_NS":         {a: strSet("1"), b: numSet("1"), want: false},
+		"BS_order":      {a: &dynamodb.AttributeValue{BS: [][]byte{{1}, {2}}}, b: &dynamodb.AttributeValue{BS: [][]byte{{2}, {1}}}, want: true},
+		"L":             {a: &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{strSet("a", "b")}}, b: &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{strSet("b", "a")}}, want: true},
+		"L_len":         {a: &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{}}, b: &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{strSet("a")}}, want: false},
+		"L_order":       {a: &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{numSet("1"), numSet("2")}}, b: &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{numSet("2"), numSet("1")}}, want: false},
+		"M":             {a: &dynamodb.AttributeValue{M: Item{"a": numSet("1", "2")}}, b: &dynamodb.AttributeValue{M: Item{"a": numSet("2", "1")}}, want: true},
+		"M_len":         {a: &dynamodb.AttributeValue{M: Item{}}, b: &dynamodb.AttributeValue{M: Item{"a": numSet("1")}}, want: false},
+		"M_missing_key": {a: &dynamodb.AttributeValue{M: Item{"a": numSet("1")}}, b: &dynamodb.AttributeValue{M: Item{"b": numSet("1")}}, want: false},
+		"empty":         {a: &dynamodb.AttributeValue{}, b: &dynamodb.AttributeValue{}, want: false},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, attrEqual(tc.a, tc.b))
+			require.Equal(t, tc.want, attrEqual(tc.b, tc.a))
+		})
+	}
+}
+
+func TestSetContains(t *testing.T) {
+	require.True(t, setContains(strSet("a", "b"), &dynamodb.AttributeValue{S: strPtr("b")}))
+	require.False(t, setContains(strSet("a", "b"), &dynamodb.AttributeValue{S: strPtr("c")}))
+	require.True(t, setContains(numSet("1", "2"), &dynamodb.AttributeValue{N: strPtr("2.0")}))
+	require.False(t, setContains(numSet("1", "2"), &dynamodb.AttributeValue{N: strPtr("x")}))
+	require.True(t, setContains(&dynamodb.AttributeValue{BS: [][]byte{{1}}}, &dynamodb.AttributeValue{B: []byte{1}}))
+	require.False(t, setContains(numSet("1"), &dynamodb.AttributeValue{S: strPtr("1")}))
+	require.False(t, setContains(&dynamodb.AttributeValue{S: strPtr("1")}, &dynamodb.AttributeValue{S: strPtr("1")}))
+}
+
+func TestAddAttr(t *testing.T) {
+	got, err := addAttr(nil, numSet("1"))
+	require.NoError(t, err)
+	require.Equal(t, numSet("1"), got)
+
+	got, err = addAttr(&dynamodb.AttributeValue{N: strPtr("1.5")}, &dynamodb.AttributeValue{N: strPtr("-3")})
+	require.NoError(t, err)
+	require.Equal(t, "-1.5", *got.N)
+
+	got, err = addAttr(strSet("a", "b"), strSet("c", "a"))
+	require.NoError(t, err)
+	require.Equal(t, strSet("a", "b", "c"), got)
+
+	got, err = addAttr(numSet("1"), numSet("1.0", "2"))
+	require.NoError(t, err)
+	require.Equal(t, numSet("1", "2"), got)
+
+	got, err = addAttr(&dynamodb.AttributeValue{BS: [][]byte{{1}}}, &dynamodb.AttributeValue{BS: [][]byte{{2}}})
+	require.NoError(t, err)
+	require.Equal(t, &dynamodb.AttributeValue{BS: [][]byte{{1}, {2}}}, got)
+}
+
+func TestAddAttrErr(t *testing.T) {
+	_, err := addAttr(strSet("a"), numSet("1"))
+	requireErrIs(t, err, ErrInvalidUpdateExpression)
+	requireErrIs(t, err, ErrInvalidType)
+
+	_, err = addAttr(&dynamodb.AttributeValue{S: strPtr("a")}, &dynamodb.AttributeValue{S: strPtr("b")})
+	requireErrIs(t, err, ErrInvalidType)
+
+	_, err = addAttr(&dynamodb.AttributeValue{N: strPtr("x")}, &dynamodb.AttributeValue{N: strPtr("1")})
+	requireErrIs(t, err, ErrInvalidNumber)
+
+	_, err = addAttr(&dynamodb.AttributeValue{N: strPtr("1")}, &dynamodb.AttributeValue{N: strPtr("x")})
+	requireErrIs(t, err, ErrInvalidNumber)
+
+	_, err = addAttr(&dynamodb.AttributeValue{N: strPtr("9E125")}, &dynamodb.AttributeValue{N: strPtr("9E125")})
+	requireErrIs(t, err, ErrInvalidNumber)
+
+	// new attributes must be numbers or sets, too
+	for _, val := range []*dynamodb.AttributeValue{
+		{S: strPtr("a")},
+		{BOOL: boolPtr(true)},
+		{M: Item{"a": {S: strPtr("b")}}},
+	} {
+		_, err = addAttr(nil, val)
+		requireErrIs(t, err, ErrInvalidUpdateExpression)
+		requireErrIs(t, err, ErrInvalidType)
+	}
+}
+
+func TestDeleteAttr(t *testing.T) {
+	got, err := deleteAttr(nil, strSet("a"))
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	got, err = deleteAttr(strSet("a", "b", "c"), strSet("b", "x"))
+	require.NoError(t, err)
+	require.Equal(t, strSet("a", "c"), got)
+
+	got, err = deleteAttr(numSet("1", "2"), numSet("2.0", "1"))
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	_, err = deleteAttr(strSet("a"), &dynamodb.AttributeValue{S: strPtr("a")})
+	requireErrIs(t, err, ErrInvalidUpdateExpression)
+	requireErrIs(t, err, ErrInvalidType)
+
+	_, err = deleteAttr(strSet("a"), numSet("1"))
+	requireErrIs(t, err, ErrInvalidType)
+}
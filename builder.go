@@ -0,0 +1,164 @@
+package dynamock
+
+import (
+	"foxygo.at/s/errs"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// Key attribute types of KeyPartDef.
+const (
+	TypeString = "string"
+	TypeNumber = "number"
+	TypeBinary = "binary"
+)
+
+// Builder builds a DB in Go rather than from a fixture file:
+//
+//	db, err := NewBuilder().
+//		Table("person").PartitionKey("id", TypeNumber).
+//		GSI("nameGSI", KeyPart("name", TypeString), KeyPart("age", TypeNumber)).
+//		Items(people...).
+//		Table("product").PartitionKey("id", TypeString).
+//		Build()
+type Builder struct {
+	tables []*TableBuilder
+}
+
+// TableBuilder builds a table of a Builder, see Builder.Table.
+type TableBuilder struct {
+	b      *Builder
+	name   string
+	schema Schema
+	items  []interface{}
+}
+
+// NewBuilder returns a Builder without tables.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// KeyPart returns the definition of a key attribute of type TypeString,
+// TypeNumber or TypeBinary for GSI and LSI.
+func KeyPart(name, typ string) KeyPartDef {
+	return KeyPartDef{Name: name, Type: typ}
+}
+
+// Table adds a table to b and returns its builder.
+func (b *Builder) Table(name string) *TableBuilder {
+	tb := &TableBuilder{b: b, name: name}
+	b.tables = append(b.tables, tb)
+	return tb
+}
+
+// Build validates and indexes the tables of b like NewDBFromReader does
+// for fixture tables. Errors name the table and the position of the item
+// as passed to Items, counting from 0 across Items calls.
+func (b *Builder) Build() (*DB, error) {
+	db := &DB{}
+	for _, tb := range b.tables {
+		table, err := tb.build()
+		if err != nil {
+			return nil, errs.Errorf("Builder: %v (table: '%s')", err, tb.name)
+		}
+		if err := db.addTable(table); err != nil {
+			return nil, errs.Errorf("Builder: %v", err)
+		}
+	}
+	return db, nil
+}
+
+// MustBuild is like Build but panics on error, e.g. for package level
+// test fixtures.
+func (b *Builder) MustBuild() *DB {
+	db, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// PartitionKey sets the partition key of the table's primary key.
+func (tb *TableBuilder) PartitionKey(name, typ string) *TableBuilder {
+	tb.schema.PrimaryKey.PartitionKey = KeyPart(name, typ)
+	return tb
+}
+
+// SortKey sets the sort key of the table's primary key.
+func (tb *TableBuilder) SortKey(name, typ string) *TableBuilder {
+	sortKey := KeyPart(name, typ)
+	tb.schema.PrimaryKey.SortKey = &sortKey
+	return tb
+}
+
+// GSI adds a global secondary index with an optional sort key.
+func (tb *TableBuilder) GSI(name string, partitionKey KeyPartDef, sortKey ...KeyPartDef) *TableBuilder {
+	tb.schema.GSIs = append(tb.schema.GSIs, keyDef(name, partitionKey, sortKey))
+	return tb
+}
+
+// LSI adds a local secondary index with sortKey. Its partition key is the
+// partition key of the table, which must be set first.
+func (tb *TableBuilder) LSI(name string, sortKey KeyPartDef) *TableBuilder {
+	lsi := keyDef(name, tb.schema.PrimaryKey.PartitionKey, []KeyPartDef{sortKey})
+	tb.schema.LSIs = append(tb.schema.LSIs, lsi)
+	return tb
+}
+
+func keyDef(name string, partitionKey KeyPartDef, sortKey []KeyPartDef) KeyDef {
+	k := KeyDef{Name: name, PartitionKey: partitionKey}
+	if len(sortKey) != 0 {
+		k.SortKey = &sortKey[0]
+	}
+	return k
+}
+
+// Items adds items to the table. Items are Go structs or maps, marshalled
+// with dynamodbattribute.MarshalMap and its dynamodbav struct tags, or
+// Item values, which are added as they are.
+func (tb *TableBuilder) Items(items ...interface{}) *TableBuilder {
+	tb.items = append(tb.items, items...)
+	return tb
+}
+
+// Table adds another table to the Builder of tb, see Builder.Table.
+func (tb *TableBuilder) Table(name string) *TableBuilder {
+	return tb.b.Table(name)
+}
+
+// Build builds the DB of the Builder of tb, see Builder.Build.
+func (tb *TableBuilder) Build() (*DB, error) {
+	return tb.b.Build()
+}
+
+// MustBuild builds the DB of the Builder of tb, see Builder.MustBuild.
+func (tb *TableBuilder) MustBuild() *DB {
+	return tb.b.MustBuild()
+}
+
+func (tb *TableBuilder) build() (*Table, error) {
+	table := &Table{name: tb.name, schema: tb.schema, format: formatJSON}
+	if err := validateTable(table); err != nil {
+		return nil, err
+	}
+	seen := map[keyStrings]bool{}
+	for i, v := range tb.items {
+		item, ok := v.(Item)
+		if !ok {
+			var err error
+			if item, err = dynamodbattribute.MarshalMap(v); err != nil {
+				return nil, errs.Errorf("%v: %v (item: %d)", ErrItemValidation, err, i)
+			}
+		}
+		if err := validateItem(item, table.schema); err != nil {
+			return nil, errs.Errorf("%v (item: %d)", err, i)
+		}
+		k, _ := getKeyStrings(item, table.schema.PrimaryKey)
+		if seen[*k] {
+			return nil, errs.Errorf("%v: %v: partitionKey '%s', sortKey '%s' (item: %d)", ErrDuplicate, ErrPrimaryKeyVal, k.PartitionKey, k.SortKey, i)
+		}
+		seen[*k] = true
+		table.items = append(table.items, item)
+	}
+	_ = table.index() // items are validated and unique
+	return table, nil
+}
@@ -0,0 +1,84 @@
+package dynamock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type person struct {
+	ID    int    `dynamodbav:"id"`
+	Name  string `dynamodbav:"name"`
+	Phone string `dynamodbav:"phone,omitempty"`
+	Age   *int   `dynamodbav:"age,omitempty"`
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestBuilder(t *testing.T) {
+	people := []interface{}{
+		person{ID: 0, Name: "Jon", Phone: "000", Age: intPtr(0)},
+		person{ID: 1, Name: "Jon", Phone: "111", Age: intPtr(11)},
+		person{ID: 2, Name: "Tom", Phone: "222", Age: intPtr(22)},
+		person{ID: 3, Name: "Bee", Phone: "333", Age: intPtr(33)},
+		person{ID: 4, Name: "Jen", Phone: "444", Age: intPtr(44)},
+		person{ID: 5, Name: "Jen", Phone: "555"},
+		&person{ID: 6, Name: "No-phone", Age: intPtr(1)},
+		map[string]interface{}{"id": 7, "name": "No-age", "phone": "777"},
+	}
+	db, err := NewBuilder().
+		Table("person").PartitionKey("id", TypeNumber).
+		GSI("nameGSI", KeyPart("name", TypeString), KeyPart("age", TypeNumber)).
+		GSI("phoneGSI", KeyPart("phone", TypeString), KeyPart("name", TypeString)).
+		Items(people...).
+		Items(Item{"id": {N: strPtr("8")}, "name": {S: strPtr("Jen")}, "phone": {S: strPtr("222")}, "age": {N: strPtr("15")}}).
+		Build()
+	require.NoError(t, err)
+	want := ReadTestdataDB(t, "db.json")
+	want.tables = map[string]*Table{"person": want.tables["person"]}
+	want.tableNames = []string{"person"}
+	require.True(t, Diff(want, db).Empty(), Diff(want, db).String())
+	require.Equal(t, want.tables["person"].byIndex, db.tables["person"].byIndex)
+	require.Equal(t, want.tables["person"].schema, db.tables["person"].schema)
+
+	db = NewBuilder().
+		Table("order").PartitionKey("customer", TypeString).SortKey("id", TypeBinary).
+		LSI("dateLSI", KeyPart("date", TypeString)).
+		Table("empty").PartitionKey("id", TypeString).
+		MustBuild()
+	require.Equal(t, []string{"order", "empty"}, db.tableNames)
+	require.Equal(t, "customer", db.tables["order"].schema.LSIs[0].PartitionKey.Name)
+	require.Contains(t, db.tables["order"].byIndex, "dateLSI")
+	require.Empty(t, NewBuilder().MustBuild().tableNames)
+}
+
+func TestBuilderErr(t *testing.T) {
+	items := []interface{}{map[string]string{"id": "1"}, map[string]string{"id": "2"}}
+	testCases := map[string]struct {
+		b    *TableBuilder
+		want error
+		msg  string
+	}{
+		"no_key":        {b: NewBuilder().Table("t"), want: ErrSchemaValidation},
+		"marshal":       {b: NewBuilder().Table("t").PartitionKey("id", TypeString).Items(items[0], struct{ C chan int }{}), want: ErrItemValidation, msg: "(item: 1)"},
+		"missing_key":   {b: NewBuilder().Table("t").PartitionKey("id", TypeString).Items(items...).Items(map[string]string{}), want: ErrMissingAttribute, msg: "(item: 2)"},
+		"bad_key_type":  {b: NewBuilder().Table("t").PartitionKey("id", TypeNumber).Items(items...), want: ErrPrimaryKeyVal, msg: "(item: 0) (table: 't')"},
+		"duplicate":     {b: NewBuilder().Table("t").PartitionKey("id", TypeString).Items(items...).Items(items[1]), want: ErrDuplicate, msg: "(item: 2)"},
+		"duplicate_gsi": {b: NewBuilder().Table("t").PartitionKey("id", TypeString).GSI("g", KeyPart("a", TypeString)).GSI("g", KeyPart("b", TypeString)), want: ErrDuplicate},
+		"duplicate_table": {
+			b:    NewBuilder().Table("t").PartitionKey("id", TypeString).Table("t").PartitionKey("id", TypeString),
+			want: ErrDuplicate,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.b.Build()
+			requireErrIs(t, err, tc.want)
+			require.Contains(t, err.Error(), tc.msg)
+			require.Panics(t, func() { tc.b.MustBuild() })
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package dynamock
+
+import (
+	"errors"
+
+	"foxygo.at/s/errs"
+)
+
+var ErrCheckpoint = errors.New("invalid checkpoint")
+
+// Checkpoint is a token for the state of all tables of a DB, see
+// DB.Checkpoint.
+type Checkpoint struct {
+	db         *DB
+	tableNames []string
+	tables     map[string]*Table
+	states     map[*Table]tableState
+}
+
+// tableState holds the schema, items and lookups of a table. They are
+// shared between the table and its checkpoints until the table's next
+// mutation, see Table.own.
+type tableState struct {
+	schema    Schema
+	items     []Item
+	byPrimary map[string]map[string]Item
+	byIndex   map[string]map[string][]Item
+}
+
+// Checkpoint returns a token for the current state of all tables that can
+// be restored with Rollback, e.g. to reset a DB between tests without
+// reading its fixture again. Checkpoints share items and lookups with
+// their tables: taking a checkpoint copies nothing and a table copies its
+// item slices and lookups, but never items, on its first mutation after a
+// checkpoint.
+func (db *DB) Checkpoint() *Checkpoint {
+	db.m.RLock()
+	defer db.m.RUnlock()
+	cp := &Checkpoint{
+		db:         db,
+		tableNames: append([]string(nil), db.tableNames...),
+		tables:     make(map[string]*Table, len(db.tables)),
+		states:     make(map[*Table]tableState, len(db.tables)),
+	}
+	for name, t := range db.tables {
+		t.m.Lock()
+		t.shared = true
+		cp.tables[name] = t
+		cp.states[t] = tableState{schema: t.schema, items: t.items, byPrimary: t.byPrimary, byIndex: t.byIndex}
+		t.m.Unlock()
+	}
+	return cp
+}
+
+// Rollback restores all tables of db to the state of cp, taken with
+// Checkpoint on db. Tables added since are removed. A checkpoint can be
+// rolled back to any number of times. Rollback is neither persisted nor
+// logged, see Persist and OpenDBWithLog.
+func (db *DB) Rollback(cp *Checkpoint) error {
+	if cp == nil {
+		return errs.Errorf("%v: Checkpoint", ErrNil)
+	}
+	if cp.db != db {
+		return errs.Errorf("%v: taken on a different DB", ErrCheckpoint)
+	}
+	db.m.Lock()
+	defer db.m.Unlock()
+	db.tableNames = append([]string(nil), cp.tableNames...)
+	db.tables = make(map[string]*Table, len(cp.tables))
+	for name, t := range cp.tables {
+		db.tables[name] = t
+		s := cp.states[t]
+		t.m.Lock()
+		t.schema, t.items, t.byPrimary, t.byIndex = s.schema, s.items, s.byPrimary, s.byIndex
+		t.shared = true
+		t.m.Unlock()
+	}
+	return nil
+}
+
+// own copies the item slices and lookups of t if they are shared with a
+// checkpoint, so that they can be mutated. Items themselves are never
+// mutated once stored and remain shared.
+func (t *Table) own() {
+	if !t.shared {
+		return
+	}
+	t.shared = false
+	t.items = append([]Item(nil), t.items...)
+	byPrimary := make(map[string]map[string]Item, len(t.byPrimary))
+	for pk, bySort := range t.byPrimary {
+		m := make(map[string]Item, len(bySort))
+		for sk, item := range bySort {
+			m[sk] = item
+		}
+		byPrimary[pk] = m
+	}
+	t.byPrimary = byPrimary
+	byIndex := make(map[string]map[string][]Item, len(t.byIndex))
+	for name, byPK := range t.byIndex {
+		m := make(map[string][]Item, len(byPK))
+		for pk, items := range byPK {
+			m[pk] = append([]Item(nil), items...)
+		}
+		byIndex[name] = m
+	}
+	t.byIndex = byIndex
+}
+
+// Clone returns an independent copy of db for e.g. parallel tests that
+// each need their own state. Like a checkpoint, the clone shares items
+// and lookups with db until either of them mutates a table, so cloning
+// costs the same for any number of items. The clone is not persisted, see
+// Persist, and keeps the Clock of db but not its recorder, faults and
+// latency.
+func (db *DB) Clone() *DB {
+	db.m.RLock()
+	defer db.m.RUnlock()
+	clone := &DB{
+		tableNames: append([]string(nil), db.tableNames...),
+		tables:     make(map[string]*Table, len(db.tables)),
+		pageSize:   db.pageSize,
+		clock:      db.clock,
+	}
+	for name, t := range db.tables {
+		t.m.Lock()
+		t.shared = true
+		clone.tables[name] = &Table{
+			name:        t.name,
+			schema:      t.schema,
+			format:      t.format,
+			createTable: t.createTable,
+			clock:       t.clock,
+			source:      t.source,
+			items:       t.items,
+			byPrimary:   t.byPrimary,
+			byIndex:     t.byIndex,
+			shared:      true,
+		}
+		t.m.Unlock()
+	}
+	return clone
+}
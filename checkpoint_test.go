@@ -0,0 +1,94 @@
+package dynamock
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func requireTablesEqual(t *testing.T, want, got *DB) {
+	t.Helper()
+	require.Equal(t, want.tableNames, got.tableNames)
+	for _, name := range want.tableNames {
+		w, g := want.tables[name], got.tables[name]
+		require.Equal(t, w.schema, g.schema, name)
+		require.Equal(t, w.items, g.items, name)
+		require.Equal(t, w.byPrimary, g.byPrimary, name)
+		require.Equal(t, w.byIndex, g.byIndex, name)
+	}
+}
+
+func mutatePeople(t *testing.T, db *DB) {
+	t.Helper()
+	_, err := db.PutItem(&dynamodb.PutItemInput{
+		TableName: strPtr("person"),
+		Item:      Item{"id": {N: strPtr("100")}, "name": {S: strPtr("Jon")}, "phone": {S: strPtr("100")}, "age": {N: strPtr("5")}},
+	})
+	require.NoError(t, err)
+	_, err = db.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 strPtr("person"),
+		Key:                       Item{"id": {N: strPtr("0")}},
+		UpdateExpression:          strPtr("SET age = :a"),
+		ExpressionAttributeValues: Item{":a": {N: strPtr("99")}},
+	})
+	require.NoError(t, err)
+	_, err = db.DeleteItem(&dynamodb.DeleteItemInput{TableName: strPtr("person"), Key: Item{"id": {N: strPtr("1")}}})
+	require.NoError(t, err)
+}
+
+func TestCheckpointRollback(t *testing.T) {
+	want := ReadTestdataDB(t, "db.json")
+	db := ReadTestdataDB(t, "db.json")
+	cp := db.Checkpoint()
+
+	mutatePeople(t, db)
+	require.NoError(t, putProduct(t, db, "9"))
+	table := &Table{name: "t", schema: Schema{PrimaryKey: KeyDef{PartitionKey: KeyPartDef{Name: "id", Type: "string"}}}}
+	_ = table.index()
+	require.NoError(t, db.addTable(table))
+	require.Len(t, db.tables["person"].byIndex["nameGSI"]["Jon"], 2)
+
+	require.NoError(t, db.Rollback(cp))
+	requireTablesEqual(t, want, db)
+
+	// a checkpoint survives mutations after a rollback to it
+	mutatePeople(t, db)
+	require.NoError(t, db.Rollback(cp))
+	requireTablesEqual(t, want, db)
+
+	// later checkpoints do not affect earlier ones
+	mutatePeople(t, db)
+	cp2 := db.Checkpoint()
+	require.NoError(t, putProduct(t, db, "9"))
+	require.NoError(t, db.Rollback(cp))
+	requireTablesEqual(t, want, db)
+	require.NoError(t, db.Rollback(cp2))
+	require.Nil(t, db.tables["product"].byPrimary["9"])
+	require.Len(t, db.tables["person"].items, len(want.tables["person"].items))
+}
+
+func TestRollbackErr(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	requireErrIs(t, db.Rollback(nil), ErrNil)
+	requireErrIs(t, db.Rollback(NewDB().Checkpoint()), ErrCheckpoint)
+}
+
+func TestClone(t *testing.T) {
+	want := ReadTestdataDB(t, "db.json")
+	db := ReadTestdataDB(t, "db.json")
+	db.pageSize = 3
+	for i := 0; i < 4; i++ {
+		clone := db.Clone()
+		t.Run("parallel", func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, 3, clone.pageSize)
+			mutatePeople(t, clone)
+			require.NoError(t, putProduct(t, clone, "9"))
+			require.Len(t, clone.tables["product"].items, len(want.tables["product"].items)+1)
+		})
+	}
+	clone := db.Clone()
+	mutatePeople(t, db)
+	requireTablesEqual(t, want, clone)
+}
@@ -0,0 +1,225 @@
+package dynamock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the time source of a DB, see DB.SetClock.
+type Clock interface {
+	Now() time.Time
+	// After waits for d to elapse and then sends the current time on the
+	// returned channel, like time.After.
+	After(d time.Duration) <-chan time.Time
+	// AfterFunc waits for d to elapse and then calls f, like
+	// time.AfterFunc.
+	AfterFunc(d time.Duration, f func()) Timer
+	// NewTicker returns a Ticker sending the current time every d, like
+	// time.NewTicker.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a timer of Clock.AfterFunc, e.g. a *time.Timer.
+type Timer interface {
+	// Stop prevents the timer from firing. It returns false if the timer
+	// has already fired or been stopped.
+	Stop() bool
+}
+
+// Ticker is a ticker of Clock.NewTicker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type realClock struct{}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (realClock) Now() time.Time                            { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time    { return time.After(d) }
+func (realClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+func (realClock) NewTicker(d time.Duration) Ticker          { return realTicker{time.NewTicker(d)} }
+func (t realTicker) C() <-chan time.Time                    { return t.t.C }
+func (t realTicker) Stop()                                  { t.t.Stop() }
+
+// Clock returns the Clock of db, by default the system clock.
+func (db *DB) Clock() Clock {
+	if db.clock == nil {
+		return realClock{}
+	}
+	return db.clock
+}
+
+// SetClock sets the Clock of db, e.g. a ManualClock that tests advance
+// instead of sleeping. The Clock times debounced writes of Persist, the
+// polls of Watch, simulated latency of SimulateLatency, the Durations of
+// recorded calls and the export time of WriteExport, also of the tables
+// of db. DB has no TTL expiry, backups, point-in-time restore or table
+// status transitions, so nothing else depends on time. Context deadlines
+// of calls expire on the Clock only if they are built from its Now, e.g.
+// context.WithDeadline(ctx, c.Now().Add(d)); a context.WithTimeout
+// deadline is relative to the system clock. SetClock must be called
+// before Persist and Watch and not concurrently with calls of db.
+func (db *DB) SetClock(c Clock) {
+	db.clock = c
+	for _, t := range db.tableList() {
+		t.setClock(c)
+	}
+}
+
+func (t *Table) setClock(c Clock) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.clock = c
+}
+
+// ManualClock is a Clock whose time only passes with Advance, so that
+// tests can step time deterministically. Context deadlines of DB calls
+// must be built from its Now to expire with Advance, see DB.SetClock.
+type ManualClock struct {
+	m       sync.Mutex
+	waiters *sync.Cond // signalled when timers are added
+	now     time.Time
+	timers  []*manualTimer
+}
+
+// manualTimer is a pending timer, ticker or After wait of a ManualClock.
+type manualTimer struct {
+	c      *ManualClock
+	due    time.Time
+	period time.Duration  // of tickers
+	ch     chan time.Time // of tickers and After waits
+	f      func()         // of AfterFunc timers
+}
+
+type manualTicker struct {
+	*manualTimer
+}
+
+// NewManualClock returns a ManualClock set to now.
+func NewManualClock(now time.Time) *ManualClock {
+	c := &ManualClock{now: now}
+	c.waiters = sync.NewCond(&c.m)
+	return c
+}
+
+// Now returns the time of c.
+func (c *ManualClock) Now() time.Time {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the time of c once Advance
+// passes d.
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	return c.add(&manualTimer{ch: make(chan time.Time, 1)}, d).ch
+}
+
+// AfterFunc calls f once Advance passes d, in the goroutine calling
+// Advance and before Advance returns.
+func (c *ManualClock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.add(&manualTimer{f: f}, d)
+}
+
+// NewTicker returns a Ticker that receives the time of c whenever Advance
+// passes another d, dropping ticks for slow receivers like time.Ticker.
+// It panics if d is not positive.
+func (c *ManualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("dynamock: non-positive interval for ManualClock.NewTicker")
+	}
+	t := &manualTimer{period: d, ch: make(chan time.Time, 1)}
+	return manualTicker{c.add(t, d)}
+}
+
+// Advance moves the time of c forward by d, firing the timers and tickers
+// due in order of their due time.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	end := c.now.Add(d)
+	for {
+		t := c.next(end)
+		if t == nil {
+			break
+		}
+		c.now = t.due
+		if t.period > 0 {
+			t.due = t.due.Add(t.period)
+			c.timers = append(c.timers, t)
+		}
+		if t.f != nil {
+			c.m.Unlock()
+			t.f()
+			c.m.Lock()
+			continue
+		}
+		select {
+		case t.ch <- c.now:
+		default:
+		}
+	}
+	c.now = end
+}
+
+// BlockUntil blocks until n timers, tickers and After waits are pending,
+// e.g. until a call in another goroutine waits for simulated latency.
+func (c *ManualClock) BlockUntil(n int) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	for len(c.timers) < n {
+		c.waiters.Wait()
+	}
+}
+
+// add adds t, due after d.
+func (c *ManualClock) add(t *manualTimer, d time.Duration) *manualTimer {
+	c.m.Lock()
+	defer c.m.Unlock()
+	t.c, t.due = c, c.now.Add(d)
+	c.timers = append(c.timers, t)
+	c.waiters.Broadcast()
+	return t
+}
+
+// next removes and returns the earliest timer due at end or nil.
+func (c *ManualClock) next(end time.Time) *manualTimer {
+	i := -1
+	for j, t := range c.timers {
+		if !t.due.After(end) && (i == -1 || t.due.Before(c.timers[i].due)) {
+			i = j
+		}
+	}
+	if i == -1 {
+		return nil
+	}
+	t := c.timers[i]
+	c.timers = append(c.timers[:i], c.timers[i+1:]...)
+	return t
+}
+
+// Stop removes t from its clock. It returns false if t already fired or
+// has been stopped.
+func (t *manualTimer) Stop() bool {
+	t.c.m.Lock()
+	defer t.c.m.Unlock()
+	for i, pending := range t.c.timers {
+		if pending == t {
+			t.c.timers = append(t.c.timers[:i], t.c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (t manualTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t manualTicker) Stop() {
+	t.manualTimer.Stop()
+}
@@ -0,0 +1,73 @@
+package dynamock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var epoch = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestManualClock(t *testing.T) {
+	c := NewManualClock(epoch)
+	require.Equal(t, epoch, c.Now())
+
+	after := c.After(time.Second)
+	var fired []time.Time
+	c.AfterFunc(2*time.Second, func() {
+		fired = append(fired, c.Now())
+		c.AfterFunc(0, func() { fired = append(fired, c.Now()) })
+	})
+	stopped := c.AfterFunc(time.Second, func() { t.Fatal("stopped timer fired") })
+	require.True(t, stopped.Stop())
+	require.False(t, stopped.Stop())
+
+	c.Advance(999 * time.Millisecond)
+	require.Empty(t, after)
+	c.Advance(time.Millisecond)
+	require.Equal(t, epoch.Add(time.Second), <-after)
+	require.Empty(t, fired)
+	c.Advance(time.Hour)
+	require.Equal(t, []time.Time{epoch.Add(2 * time.Second), epoch.Add(2 * time.Second)}, fired)
+	require.Equal(t, epoch.Add(time.Hour+time.Second), c.Now())
+}
+
+func TestManualClockTicker(t *testing.T) {
+	c := NewManualClock(epoch)
+	ticker := c.NewTicker(time.Second)
+	c.Advance(time.Second)
+	require.Equal(t, epoch.Add(time.Second), <-ticker.C())
+	c.Advance(3 * time.Second) // ticks are dropped for slow receivers
+	require.Equal(t, epoch.Add(2*time.Second), <-ticker.C())
+	require.Empty(t, ticker.C())
+	ticker.Stop()
+	c.Advance(time.Hour)
+	require.Empty(t, ticker.C())
+	require.Panics(t, func() { c.NewTicker(0) })
+}
+
+func TestManualClockBlockUntil(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	c := NewManualClock(epoch)
+	db.SetClock(c)
+	db.SimulateLatency(1, Latency{Default: FixedLatency(time.Minute)})
+	r := db.Record()
+	done := make(chan error)
+	go func() { done <- getProduct(db, "1") }()
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	require.NoError(t, <-done)
+	require.Equal(t, time.Minute, r.Calls()[0].Duration)
+}
+
+func TestRealClockTimers(t *testing.T) {
+	c := NewDB().Clock()
+	fired := make(chan bool)
+	c.AfterFunc(0, func() { fired <- true })
+	require.True(t, <-fired)
+	require.True(t, c.AfterFunc(time.Hour, func() {}).Stop())
+	ticker := c.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+}
@@ -0,0 +1,379 @@
+package dynamock
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"foxygo.at/s/errs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrCloudFormation = errors.New("invalid CloudFormation template")
+
+const (
+	cfnTableType       = "AWS::DynamoDB::Table"
+	cfnSimpleTableType = "AWS::Serverless::SimpleTable"
+)
+
+// cfnTableProperties are the table resource properties used by dynamock.
+// Intrinsic functions are only resolved within these properties, so that
+// unsupported functions elsewhere, e.g. in Tags, do not fail loading.
+var cfnTableProperties = []string{
+	"TableName", "PrimaryKey", "KeySchema", "AttributeDefinitions",
+	"GlobalSecondaryIndexes", "LocalSecondaryIndexes",
+	"StreamSpecification", "TimeToLiveSpecification",
+}
+
+var samKeyTypes = map[string]string{
+	"String": "string",
+	"Number": "number",
+	"Binary": "binary",
+}
+
+var cfnSubVar = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// NewDBFromCloudFormation creates a DB with an empty table for every
+// AWS::DynamoDB::Table and AWS::Serverless::SimpleTable resource of a
+// CloudFormation or SAM template in YAML or JSON. Tables are named by
+// their TableName property or, if it is not set, their logical ID. Ref
+// and Sub intrinsic functions in table properties are resolved from
+// params, falling back to the Default of template Parameters.
+func NewDBFromCloudFormation(r io.Reader, params map[string]string) (*DB, error) {
+	db := NewDB()
+	if err := db.loadCloudFormation(r, params); err != nil {
+		return nil, errs.Errorf("NewDBFromCloudFormation: %v", err)
+	}
+	return db, nil
+}
+
+// LoadCloudFormation adds the tables of the CloudFormation template file
+// fname to db, see NewDBFromCloudFormation.
+func (db *DB) LoadCloudFormation(fname string, params map[string]string) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return errs.Errorf("LoadCloudFormation: %v: %v", ErrCloudFormation, err)
+	}
+	defer f.Close()
+	if err := db.loadCloudFormation(f, params); err != nil {
+		return errs.Errorf("LoadCloudFormation: %v (file: '%s')", err, fname)
+	}
+	return nil
+}
+
+func (db *DB) loadCloudFormation(r io.Reader, params map[string]string) error {
+	doc := yaml.Node{}
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return errs.Errorf("%v: %v", ErrCloudFormation, err)
+	}
+	if len(doc.Content) == 0 { // empty or comment only
+		return errs.Errorf("%v: %v: empty template", ErrCloudFormation, ErrSchemaValidation)
+	}
+	root := doc.Content[0]
+	resources := cfnMappingValue(root, "Resources")
+	if resources == nil || resources.Kind != yaml.MappingNode {
+		return errs.Errorf("%v: %v: Resources", ErrCloudFormation, ErrNil)
+	}
+	resolver := cfnResolver{}
+	for name, p := range cfnMap(cfnValue(cfnMappingValue(root, "Parameters"))) {
+		if def, ok := cfnMap(p)["Default"]; ok {
+			resolver[name] = fmt.Sprint(def)
+		}
+	}
+	for name, v := range params {
+		resolver[name] = v
+	}
+	// Resources are walked as YAML nodes to keep the template's order.
+	for i := 0; i+1 < len(resources.Content); i += 2 {
+		logicalID := resources.Content[i].Value
+		resource := cfnMap(cfnValue(resources.Content[i+1]))
+		typ, _ := resource["Type"].(string)
+		if typ != cfnTableType && typ != cfnSimpleTableType {
+			continue
+		}
+		table, err := resolver.table(logicalID, typ, cfnMap(resource["Properties"]))
+		if err != nil {
+			return errs.Errorf("%v (resource: '%s')", err, logicalID)
+		}
+		if err := db.addTable(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cfnMappingValue returns the value node of key in a mapping node or nil.
+func cfnMappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// cfnValue converts a YAML node to plain values as decoded from JSON.
+// Short form intrinsic functions such as `!Sub "${Env}-orders"` are
+// converted to their long form, {"Fn::Sub": "${Env}-orders"}.
+func cfnValue(node *yaml.Node) interface{} {
+	if node == nil {
+		return nil
+	}
+	if strings.HasPrefix(node.Tag, "!") && !strings.HasPrefix(node.Tag, "!!") {
+		fn := "Fn::" + node.Tag[1:]
+		if node.Tag == "!Ref" {
+			fn = "Ref"
+		}
+		if node.Kind == yaml.ScalarNode {
+			return map[string]interface{}{fn: node.Value}
+		}
+		n := *node
+		n.Tag = ""
+		return map[string]interface{}{fn: cfnValue(&n)}
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			m[node.Content[i].Value] = cfnValue(node.Content[i+1])
+		}
+		return m
+	case yaml.SequenceNode:
+		l := make([]interface{}, len(node.Content))
+		for i, n := range node.Content {
+			l[i] = cfnValue(n)
+		}
+		return l
+	case yaml.AliasNode:
+		return cfnValue(node.Alias)
+	}
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return node.Value
+	}
+	return v
+}
+
+func cfnMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func cfnList(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
+
+func cfnString(v interface{}) *string {
+	if v == nil {
+		return nil
+	}
+	s := fmt.Sprint(v)
+	return &s
+}
+
+// cfnBool accepts both booleans and the strings CloudFormation allows
+// for them.
+func cfnBool(v interface{}) bool {
+	return fmt.Sprint(v) == "true"
+}
+
+// cfnResolver resolves the Ref and Fn::Sub intrinsic functions with
+// parameter values.
+type cfnResolver map[string]string
+
+func (c cfnResolver) resolve(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if fn, arg, ok := cfnIntrinsic(v); ok {
+			switch fn {
+			case "Ref":
+				return c.ref(arg)
+			case "Fn::Sub":
+				return c.sub(arg)
+			}
+			return nil, errs.Errorf("%v: %v: %s", ErrCloudFormation, ErrUnimpl, fn)
+		}
+		m := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			r, err := c.resolve(elem)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = r
+		}
+		return m, nil
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, elem := range v {
+			r, err := c.resolve(elem)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = r
+		}
+		return l, nil
+	}
+	return v, nil
+}
+
+func cfnIntrinsic(m map[string]interface{}) (string, interface{}, bool) {
+	if len(m) != 1 {
+		return "", nil, false
+	}
+	for k, v := range m {
+		if k == "Ref" || strings.HasPrefix(k, "Fn::") {
+			return k, v, true
+		}
+	}
+	return "", nil, false
+}
+
+func (c cfnResolver) ref(arg interface{}) (string, error) {
+	name, _ := arg.(string)
+	v, ok := c[name]
+	if !ok {
+		return "", errs.Errorf("%v: Ref: unknown parameter '%v'", ErrCloudFormation, arg)
+	}
+	return v, nil
+}
+
+// sub resolves Fn::Sub with either a string argument or a list of a
+// string and a map of additional variables.
+func (c cfnResolver) sub(arg interface{}) (string, error) {
+	vars := cfnResolver{}
+	for k, v := range c {
+		vars[k] = v
+	}
+	s, ok := arg.(string)
+	if l := cfnList(arg); len(l) == 2 {
+		s, ok = l[0].(string)
+		for k, v := range cfnMap(l[1]) {
+			r, err := c.resolve(v)
+			if err != nil {
+				return "", err
+			}
+			vars[k] = fmt.Sprint(r)
+		}
+	}
+	if !ok {
+		return "", errs.Errorf("%v: Fn::Sub: invalid argument '%v'", ErrCloudFormation, arg)
+	}
+	var err error
+	result := cfnSubVar.ReplaceAllStringFunc(s, func(match string) string {
+		name := match[2 : len(match)-1]
+		if strings.HasPrefix(name, "!") {
+			return "${" + name[1:] + "}"
+		}
+		v, ok := vars[name]
+		if !ok && err == nil {
+			err = errs.Errorf("%v: Fn::Sub: unknown variable '%s'", ErrCloudFormation, name)
+		}
+		return v
+	})
+	return result, err
+}
+
+func (c cfnResolver) table(logicalID, typ string, props map[string]interface{}) (*Table, error) {
+	resolved := map[string]interface{}{}
+	for _, k := range cfnTableProperties {
+		if v, ok := props[k]; ok {
+			r, err := c.resolve(v)
+			if err != nil {
+				return nil, errs.Errorf("%v (property: '%s')", err, k)
+			}
+			resolved[k] = r
+		}
+	}
+	name := logicalID
+	if v, ok := resolved["TableName"]; ok {
+		name = fmt.Sprint(v)
+	}
+	var schema Schema
+	var err error
+	if typ == cfnSimpleTableType {
+		schema, err = samSimpleTableSchema(cfnMap(resolved["PrimaryKey"]))
+	} else {
+		schema, err = SchemaFromCreateTableInput(cfnCreateTableInput(resolved))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ttl := cfnMap(resolved["TimeToLiveSpecification"]); cfnBool(ttl["Enabled"]) {
+		schema.TTLAttribute = aws.StringValue(cfnString(ttl["AttributeName"]))
+	}
+	table := &Table{name: name, schema: schema}
+	if err := validateTable(table); err != nil {
+		return nil, err
+	}
+	_ = table.index() // cannot fail without items
+	return table, nil
+}
+
+// cfnCreateTableInput converts the resolved properties of an
+// AWS::DynamoDB::Table resource, which mirror the CreateTable request.
+func cfnCreateTableInput(props map[string]interface{}) *dynamodb.CreateTableInput {
+	in := &dynamodb.CreateTableInput{KeySchema: cfnKeySchema(props["KeySchema"])}
+	for _, v := range cfnList(props["AttributeDefinitions"]) {
+		ad := cfnMap(v)
+		in.AttributeDefinitions = append(in.AttributeDefinitions, &dynamodb.AttributeDefinition{
+			AttributeName: cfnString(ad["AttributeName"]),
+			AttributeType: cfnString(ad["AttributeType"]),
+		})
+	}
+	for _, v := range cfnList(props["GlobalSecondaryIndexes"]) {
+		gsi := cfnMap(v)
+		in.GlobalSecondaryIndexes = append(in.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndex{
+			IndexName: cfnString(gsi["IndexName"]),
+			KeySchema: cfnKeySchema(gsi["KeySchema"]),
+		})
+	}
+	for _, v := range cfnList(props["LocalSecondaryIndexes"]) {
+		lsi := cfnMap(v)
+		in.LocalSecondaryIndexes = append(in.LocalSecondaryIndexes, &dynamodb.LocalSecondaryIndex{
+			IndexName: cfnString(lsi["IndexName"]),
+			KeySchema: cfnKeySchema(lsi["KeySchema"]),
+		})
+	}
+	// In CloudFormation a StreamSpecification always enables the stream.
+	if ss := cfnMap(props["StreamSpecification"]); ss != nil {
+		in.StreamSpecification = &dynamodb.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: cfnString(ss["StreamViewType"]),
+		}
+	}
+	return in
+}
+
+func cfnKeySchema(v interface{}) []*dynamodb.KeySchemaElement {
+	var keySchema []*dynamodb.KeySchemaElement
+	for _, e := range cfnList(v) {
+		kse := cfnMap(e)
+		keySchema = append(keySchema, &dynamodb.KeySchemaElement{
+			AttributeName: cfnString(kse["AttributeName"]),
+			KeyType:       cfnString(kse["KeyType"]),
+		})
+	}
+	return keySchema
+}
+
+// samSimpleTableSchema returns the schema of an
+// AWS::Serverless::SimpleTable, which has a partition key only that
+// defaults to the string attribute "id".
+func samSimpleTableSchema(pk map[string]interface{}) (Schema, error) {
+	name, typ := "id", "String"
+	if pk != nil {
+		name = aws.StringValue(cfnString(pk["Name"]))
+		typ = aws.StringValue(cfnString(pk["Type"]))
+	}
+	t, ok := samKeyTypes[typ]
+	if !ok {
+		return Schema{}, errs.Errorf("%v: %v: PrimaryKey type '%s'", ErrSchemaValidation, ErrUnknownType, typ)
+	}
+	return Schema{PrimaryKey: KeyDef{PartitionKey: KeyPartDef{Name: name, Type: t}}}, nil
+}
@@ -0,0 +1,138 @@
+package dynamock
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCloudFormationYAML(t *testing.T) {
+	db := NewDB()
+	err := db.LoadCloudFormation(filepath.Join("testdata", "cloudformation.yaml"), map[string]string{"Env": "test"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"test-order", "SessionTable", "CounterTable"}, db.tableNames)
+
+	want := Schema{
+		PrimaryKey: KeyDef{
+			PartitionKey: KeyPartDef{Name: "customer", Type: "string"},
+			SortKey:      &KeyPartDef{Name: "created", Type: "number"},
+		},
+		GSIs: []KeyDef{{Name: "byStatus", PartitionKey: KeyPartDef{Name: "status", Type: "string"}}},
+		LSIs: []KeyDef{{
+			Name:         "byCustomerStatus",
+			PartitionKey: KeyPartDef{Name: "customer", Type: "string"},
+			SortKey:      &KeyPartDef{Name: "status", Type: "string"},
+		}},
+		TTLAttribute:   "expires",
+		StreamViewType: "NEW_AND_OLD_IMAGES",
+	}
+	order := db.tables["test-order"]
+	order.schema.gsis = nil
+	require.Equal(t, want, order.schema)
+	require.Empty(t, order.items)
+
+	in := want.CreateTableInput("test-order")
+	require.True(t, *in.StreamSpecification.StreamEnabled)
+	schema, err := SchemaFromCreateTableInput(in)
+	require.NoError(t, err)
+	require.Equal(t, "NEW_AND_OLD_IMAGES", schema.StreamViewType)
+
+	require.Equal(t, KeyPartDef{Name: "token", Type: "binary"}, db.tables["SessionTable"].schema.PrimaryKey.PartitionKey)
+	require.Equal(t, KeyPartDef{Name: "id", Type: "string"}, db.tables["CounterTable"].schema.PrimaryKey.PartitionKey)
+
+	_, err = order.Put(Item{
+		"customer": {S: strPtr("c1")},
+		"created":  {N: strPtr("1")},
+		"status":   {S: strPtr("open")},
+	})
+	require.NoError(t, err)
+}
+
+func TestNewDBFromCloudFormationJSON(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "cloudformation.json"))
+	require.NoError(t, err)
+	defer f.Close()
+	db, err := NewDBFromCloudFormation(f, map[string]string{"Env": "prod"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"prod-product", "price"}, db.tableNames)
+	require.Equal(t, "", db.tables["prod-product"].schema.TTLAttribute)
+}
+
+func TestCloudFormationSub(t *testing.T) {
+	tmpl := `
+Resources:
+  T:
+    Type: AWS::DynamoDB::Table
+    Properties:
+      TableName: !Sub
+        - "${AWS::StackName}-${!Literal}-${Suffix}"
+        - Suffix: !Ref Env
+      AttributeDefinitions: [{AttributeName: &id id, AttributeType: !!int 1x}]
+      KeySchema: [{AttributeName: *id, KeyType: HASH}]
+`
+	_, err := NewDBFromCloudFormation(strings.NewReader(tmpl), map[string]string{"AWS::StackName": "stack", "Env": "dev"})
+	// the invalid !!int scalar is kept as string "1x" and fails validation
+	requireErrIs(t, err, ErrUnknownType)
+	require.Contains(t, err.Error(), "'1x'")
+
+	tmpl = strings.Replace(tmpl, "!!int 1x", "S", 1)
+	db, err := NewDBFromCloudFormation(strings.NewReader(tmpl), map[string]string{"AWS::StackName": "stack", "Env": "dev"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"stack-${Literal}-dev"}, db.tableNames)
+}
+
+func TestCloudFormationErr(t *testing.T) {
+	table := func(props string) string {
+		return "Resources:\n  T:\n    Type: AWS::DynamoDB::Table\n    Properties:\n" + props
+	}
+	keys := "      AttributeDefinitions: [{AttributeName: id, AttributeType: S}]\n      KeySchema: [{AttributeName: id, KeyType: HASH}]\n"
+	testCases := map[string]struct {
+		tmpl string
+		want error
+	}{
+		"bad_yaml":      {tmpl: "Resources: [", want: ErrCloudFormation},
+		"empty":         {tmpl: "", want: ErrCloudFormation},
+		"comment_only":  {tmpl: "# no resources\n", want: ErrSchemaValidation},
+		"no_resources":  {tmpl: "Parameters: {}", want: ErrNil},
+		"list":          {tmpl: "[1, 2]", want: ErrNil},
+		"unknown_ref":   {tmpl: table("      TableName: !Ref Missing\n" + keys), want: ErrCloudFormation},
+		"unknown_sub":   {tmpl: table("      TableName: !Sub ${Missing}\n" + keys), want: ErrCloudFormation},
+		"bad_sub":       {tmpl: table("      TableName: !Sub [1, {}]\n" + keys), want: ErrCloudFormation},
+		"bad_sub_var":   {tmpl: table("      TableName: !Sub [x, {A: !Ref Missing}]\n" + keys), want: ErrCloudFormation},
+		"unimpl_fn":     {tmpl: table("      TableName: !Join ['-', [a, b]]\n" + keys), want: ErrUnimpl},
+		"unimpl_nested": {tmpl: table("      KeySchema: [{AttributeName: !GetAtt A.B, KeyType: HASH}]\n"), want: ErrUnimpl},
+		"missing_key":   {tmpl: table("      TableName: t\n"), want: ErrSchemaValidation},
+		"missing_type":  {tmpl: table("      AttributeDefinitions: [{AttributeName: id}]\n"), want: ErrNil},
+		"bad_lsi": {
+			tmpl: table(keys + "      LocalSecondaryIndexes: [{IndexName: lsi, KeySchema: [{AttributeName: id, KeyType: HASH}]}]\n"),
+			want: ErrSchemaValidation,
+		},
+		"sam_type": {
+			tmpl: "Resources:\n  T:\n    Type: AWS::Serverless::SimpleTable\n    Properties:\n      PrimaryKey: {Name: id, Type: Boolean}\n",
+			want: ErrUnknownType,
+		},
+		"duplicate": {
+			tmpl: "Resources:\n  T:\n    Type: AWS::Serverless::SimpleTable\n  U:\n    Type: AWS::Serverless::SimpleTable\n    Properties: {TableName: T}\n",
+			want: ErrDuplicate,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := NewDBFromCloudFormation(strings.NewReader(tc.tmpl), nil)
+			requireErrIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestLoadCloudFormationErr(t *testing.T) {
+	db := NewDB()
+	err := db.LoadCloudFormation(filepath.Join("testdata", "MISSING.yaml"), nil)
+	requireErrIs(t, err, ErrCloudFormation)
+
+	err = db.LoadCloudFormation(filepath.Join("testdata", "cloudformation.json"), nil)
+	requireErrIs(t, err, ErrCloudFormation)
+}
@@ -1,8 +1,8 @@
 package dynamock
 
 import (
+	"bytes"
 	"regexp"
-	"strconv"
 	"strings"
 
 	"foxygo.at/s/errs"
@@ -13,10 +13,14 @@ var (
 	ErrInvalidKeyCondition = errs.Errorf("invalid key condition")
 	ErrSubstitution        = errs.Errorf("missing substitution")
 
-	reExprName = regexp.MustCompile(`^#?[0-9A-Za-z_-]+$`)
-	reExprVal  = regexp.MustCompile(`^:[0-9A-Za-z_-]+$`)
+	reExprName = regexp.MustCompile(`^#?` + exprNameChars + `$`)
+	reExprVal  = regexp.MustCompile(`^:` + exprNameChars + `$`)
 )
 
+// exprNameChars matches the characters of attribute names and of the
+// placeholders of ExpressionAttributeNames and ExpressionAttributeValues.
+const exprNameChars = `[0-9A-Za-z_-]+`
+
 type op int
 
 const (
@@ -27,6 +31,7 @@ const (
 	lessEq
 	between
 	beginsWith
+	notEq
 )
 
 func (o op) String() string {
@@ -45,6 +50,8 @@ func (o op) String() string {
 		return "BETWEEN"
 	case beginsWith:
 		return "begins_with"
+	case notEq:
+		return "<>"
 	}
 	return "UNKNOWN"
 }
@@ -67,25 +74,27 @@ func (k *keyCond) checkN(av *dynamodb.AttributeValue) bool {
 	if av == nil || k.av == nil || av.N == nil || k.av.N == nil {
 		return false
 	}
-	f1, _ := strconv.ParseFloat(*av.N, 64)
-	f2, _ := strconv.ParseFloat(*k.av.N, 64)
+	c, ok := cmpNumberStrings(*av.N, *k.av.N)
+	if !ok {
+		return false
+	}
 	switch k.op {
 	case eq:
-		return f1 == f2
+		return c == 0
 	case greater:
-		return f1 > f2
+		return c > 0
 	case less:
-		return f1 < f2
+		return c < 0
 	case greaterEq:
-		return f1 >= f2
+		return c >= 0
 	case lessEq:
-		return f1 <= f2
+		return c <= 0
 	case between:
 		if k.av2 == nil || k.av2.N == nil {
 			return false
 		}
-		f3, _ := strconv.ParseFloat(*k.av2.N, 64)
-		return f2 <= f1 && f1 <= f3
+		c2, ok := cmpNumberStrings(*av.N, *k.av2.N)
+		return ok && c >= 0 && c2 <= 0
 	}
 	return false
 }
@@ -118,6 +127,34 @@ func (k *keyCond) checkS(av *dynamodb.AttributeValue) bool {
 	return false
 }
 
+func (k *keyCond) checkB(av *dynamodb.AttributeValue) bool {
+	if av == nil || k.av == nil || av.B == nil || k.av.B == nil {
+		return false
+	}
+	b1 := av.B
+	b2 := k.av.B
+	switch k.op {
+	case eq:
+		return bytes.Equal(b1, b2)
+	case greater:
+		return bytes.Compare(b1, b2) > 0
+	case less:
+		return bytes.Compare(b1, b2) < 0
+	case greaterEq:
+		return bytes.Compare(b1, b2) >= 0
+	case lessEq:
+		return bytes.Compare(b1, b2) <= 0
+	case between:
+		if k.av2 == nil || k.av2.B == nil {
+			return false
+		}
+		return bytes.Compare(b2, b1) <= 0 && bytes.Compare(b1, k.av2.B) <= 0
+	case beginsWith:
+		return bytes.HasPrefix(b1, b2)
+	}
+	return false
+}
+
 func (k *keyCond) Check(item Item) bool {
 	av := item[k.keyName]
 	if av == nil {
@@ -129,6 +166,9 @@ func (k *keyCond) Check(item Item) bool {
 	if av.N != nil {
 		return k.checkN(av)
 	}
+	if av.B != nil {
+		return k.checkB(av)
+	}
 	return false
 }
 
@@ -67,6 +67,7 @@ func TestOpString(t *testing.T) {
 	require.Equal(t, "<=", lessEq.String())
 	require.Equal(t, "BETWEEN", between.String())
 	require.Equal(t, "begins_with", beginsWith.String())
+	require.Equal(t, "<>", notEq.String())
 	require.Equal(t, "UNKNOWN", op(255).String())
 }
 
@@ -123,6 +124,65 @@ func TestKeyCondCheck(t *testing.T) {
 	require.False(t, k.Check(Item{"id": avN}))
 }
 
+func TestKeyCondCheckN(t *testing.T) {
+	avN := &dynamodb.AttributeValue{N: strPtr("1.0")}
+	k := keyCond{op: eq, keyName: "n", av: &dynamodb.AttributeValue{N: strPtr("1")}}
+	require.True(t, k.Check(Item{"n": avN}))
+
+	big := &dynamodb.AttributeValue{N: strPtr("99999999999999999999999999999999999999")}
+	bigger := &dynamodb.AttributeValue{N: strPtr("1E38")}
+	k = keyCond{op: less, keyName: "n", av: bigger}
+	require.True(t, k.Check(Item{"n": big}))
+
+	k = keyCond{op: between, keyName: "n", av: avN, av2: big}
+	require.True(t, k.Check(Item{"n": big}))
+	require.False(t, k.Check(Item{"n": bigger}))
+
+	k = keyCond{op: between, keyName: "n", av: avN, av2: &dynamodb.AttributeValue{N: strPtr("x")}}
+	require.False(t, k.Check(Item{"n": avN}))
+
+	k = keyCond{op: eq, keyName: "n", av: &dynamodb.AttributeValue{N: strPtr("x")}}
+	require.False(t, k.Check(Item{"n": avN}))
+}
+
+func TestKeyCondCheckB(t *testing.T) {
+	av := &dynamodb.AttributeValue{B: []byte{0x00, 0x01}}
+	av2 := &dynamodb.AttributeValue{B: []byte{0x01}}
+	k := keyCond{op: eq, keyName: "ts", av: av}
+	require.True(t, k.Check(Item{"ts": av}))
+	require.False(t, k.Check(Item{"ts": av2}))
+
+	k = keyCond{op: greater, keyName: "ts", av: av}
+	require.True(t, k.Check(Item{"ts": av2}))
+
+	k = keyCond{op: less, keyName: "ts", av: av2}
+	require.True(t, k.Check(Item{"ts": av}))
+
+	k = keyCond{op: greaterEq, keyName: "ts", av: av}
+	require.True(t, k.Check(Item{"ts": av}))
+
+	k = keyCond{op: lessEq, keyName: "ts", av: av}
+	require.True(t, k.Check(Item{"ts": av}))
+	require.False(t, k.Check(Item{"ts": av2}))
+
+	k = keyCond{op: between, keyName: "ts", av: av}
+	require.False(t, k.Check(Item{"ts": av}))
+
+	k = keyCond{op: between, keyName: "ts", av: av, av2: av2}
+	require.True(t, k.Check(Item{"ts": &dynamodb.AttributeValue{B: []byte{0x00, 0xff}}}))
+	require.False(t, k.Check(Item{"ts": &dynamodb.AttributeValue{B: []byte{0x01, 0x00}}}))
+
+	k = keyCond{op: beginsWith, keyName: "ts", av: &dynamodb.AttributeValue{B: []byte{0x00}}}
+	require.True(t, k.Check(Item{"ts": av}))
+	require.False(t, k.Check(Item{"ts": av2}))
+
+	k = keyCond{op: op(255), keyName: "ts", av: av}
+	require.False(t, k.Check(Item{"ts": av}))
+
+	k = keyCond{op: eq, keyName: "ts", av: &dynamodb.AttributeValue{S: strPtr("1")}}
+	require.False(t, k.Check(Item{"ts": av}))
+}
+
 func TestParseKeyCondExprStrErr(t *testing.T) {
 	_, err := parseKeyCondExprStr(nil)
 	requireErrIs(t, err, ErrInvalidKeyCondition)
@@ -0,0 +1,559 @@
+package dynamock
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"foxygo.at/s/errs"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+var (
+	ErrInvalidCondition       = errs.Errorf("invalid condition expression")
+	ErrConditionalCheckFailed = errors.New("conditional request failed")
+
+	// reExprToken matches names and placeholders with the rules of
+	// reExprName and reExprVal.
+	reExprToken = regexp.MustCompile(`^[:#]?` + exprNameChars)
+)
+
+// condition is a parsed ConditionExpression or FilterExpression.
+type condition interface {
+	eval(item Item) bool
+}
+
+type andCond struct{ left, right condition }
+type orCond struct{ left, right condition }
+type notCond struct{ cond condition }
+
+func (c *andCond) eval(item Item) bool { return c.left.eval(item) && c.right.eval(item) }
+func (c *orCond) eval(item Item) bool  { return c.left.eval(item) || c.right.eval(item) }
+func (c *notCond) eval(item Item) bool { return !c.cond.eval(item) }
+
+type cmpCond struct {
+	op          op
+	left, right *operand
+}
+
+func (c *cmpCond) eval(item Item) bool {
+	left, right := c.left.eval(item), c.right.eval(item)
+	if left == nil || right == nil {
+		return false
+	}
+	switch c.op {
+	case eq:
+		return attrEqual(left, right)
+	case notEq:
+		return !attrEqual(left, right)
+	}
+	cmp, ok := cmpAttr(left, right)
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case greater:
+		return cmp > 0
+	case less:
+		return cmp < 0
+	case greaterEq:
+		return cmp >= 0
+	}
+	return cmp <= 0
+}
+
+type betweenCond struct {
+	val, low, high *operand
+}
+
+func (c *betweenCond) eval(item Item) bool {
+	val, low, high := c.val.eval(item), c.low.eval(item), c.high.eval(item)
+	if val == nil || low == nil || high == nil {
+		return false
+	}
+	c1, ok1 := cmpAttr(val, low)
+	c2, ok2 := cmpAttr(val, high)
+	return ok1 && ok2 && c1 >= 0 && c2 <= 0
+}
+
+type inCond struct {
+	val  *operand
+	list []*operand
+}
+
+func (c *inCond) eval(item Item) bool {
+	val := c.val.eval(item)
+	if val == nil {
+		return false
+	}
+	for _, o := range c.list {
+		if attrEqual(val, o.eval(item)) {
+			return true
+		}
+	}
+	return false
+}
+
+type funcCond struct {
+	name string
+	path attrPath
+	arg  *operand
+}
+
+func (c *funcCond) eval(item Item) bool {
+	attr := c.path.resolve(item)
+	switch c.name {
+	case "attribute_exists":
+		return attr != nil
+	case "attribute_not_exists":
+		return attr == nil
+	}
+	arg := c.arg.eval(item)
+	if attr == nil || arg == nil {
+		return false
+	}
+	switch c.name {
+	case "attribute_type":
+		return arg.S != nil && attrType(attr) == *arg.S
+	case "begins_with":
+		switch {
+		case attr.S != nil && arg.S != nil:
+			return strings.HasPrefix(*attr.S, *arg.S)
+		case attr.B != nil && arg.B != nil:
+			return bytes.HasPrefix(attr.B, arg.B)
+		}
+		return false
+	}
+	// contains
+	switch {
+	case attr.S != nil && arg.S != nil:
+		return strings.Contains(*attr.S, *arg.S)
+	case attr.L != nil:
+		for _, a := range attr.L {
+			if attrEqual(a, arg) {
+				return true
+			}
+		}
+		return false
+	}
+	return setContains(attr, arg)
+}
+
+// checkCondition returns ErrConditionalCheckFailed if item does not
+// satisfy c. item is nil if no item exists for the key.
+func checkCondition(c condition, item Item) error {
+	if c == nil || c.eval(item) {
+		return nil
+	}
+	return ErrConditionalCheckFailed
+}
+
+func filterItems(items []Item, c condition) []Item {
+	if c == nil {
+		return items
+	}
+	result := []Item{}
+	for _, item := range items {
+		if c.eval(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// operand is an attribute path, an expression attribute value or the
+// size function applied to an attribute path.
+type operand struct {
+	path attrPath
+	val  *dynamodb.AttributeValue
+	size bool
+}
+
+func (o *operand) eval(item Item) *dynamodb.AttributeValue {
+	if o.val != nil {
+		return o.val
+	}
+	attr := o.path.resolve(item)
+	if !o.size || attr == nil {
+		return attr
+	}
+	size, ok := attrLen(attr)
+	if !ok {
+		return nil
+	}
+	s := strconv.Itoa(size)
+	return &dynamodb.AttributeValue{N: &s}
+}
+
+type pathElem struct {
+	name  string
+	index int // list index, if name is empty
+}
+
+// attrPath is a document path to a possibly nested attribute, e.g.
+// a.b[1].c.
+type attrPath []pathElem
+
+func (p attrPath) resolve(item Item) *dynamodb.AttributeValue {
+	attr := item[p[0].name]
+	for _, e := range p[1:] {
+		switch {
+		case attr == nil:
+			return nil
+		case e.name == "":
+			if e.index >= len(attr.L) {
+				return nil
+			}
+			attr = attr.L[e.index]
+		default:
+			attr = attr.M[e.name]
+		}
+	}
+	return attr
+}
+
+// attrLen returns the result of the size function: the length of a string
+// or binary and the number of elements of a set, list or map.
+func attrLen(attr *dynamodb.AttributeValue) (int, bool) {
+	switch {
+	case attr.S != nil:
+		return len(*attr.S), true
+	case attr.B != nil:
+		return len(attr.B), true
+	case attr.L != nil:
+		return len(attr.L), true
+	case attr.M != nil:
+		return len(attr.M), true
+	}
+	setType, keys := setKeys(attr)
+	return len(keys), setType != ""
+}
+
+func attrType(attr *dynamodb.AttributeValue) string {
+	switch {
+	case attr.S != nil:
+		return "S"
+	case attr.N != nil:
+		return "N"
+	case attr.B != nil:
+		return "B"
+	case attr.BOOL != nil:
+		return "BOOL"
+	case attr.NULL != nil:
+		return "NULL"
+	case attr.L != nil:
+		return "L"
+	case attr.M != nil:
+		return "M"
+	}
+	setType, _ := setKeys(attr)
+	return setType
+}
+
+// cmpAttr compares two strings, numbers or binaries of the same type.
+func cmpAttr(a, b *dynamodb.AttributeValue) (int, bool) {
+	switch {
+	case a.S != nil && b.S != nil:
+		return strings.Compare(*a.S, *b.S), true
+	case a.N != nil && b.N != nil:
+		return cmpNumberStrings(*a.N, *b.N)
+	case a.B != nil && b.B != nil:
+		return bytes.Compare(a.B, b.B), true
+	}
+	return 0, false
+}
+
+// parseCondition parses ConditionExpressions and FilterExpressions
+// containing comparisons (= <> < <= > >=), BETWEEN, IN, the functions
+// attribute_exists, attribute_not_exists, attribute_type, begins_with,
+// contains and size, combined with AND, OR, NOT and parentheses.
+// valueSub: ExpressionAttributeValues
+// nameSub: ExpressionAttributeNames
+func parseCondition(s *string, valueSub Item, nameSub map[string]*string) (condition, error) {
+	if s == nil {
+		return nil, nil
+	}
+	tokens, err := tokenizeCondition(*s)
+	if err != nil {
+		return nil, err
+	}
+	p := &condParser{tokens: tokens, valueSub: valueSub, nameSub: nameSub}
+	c, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, errs.Errorf("%v: unexpected '%s'", ErrInvalidCondition, p.tokens[p.pos])
+	}
+	return c, nil
+}
+
+func tokenizeCondition(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("()[],.=", c) != -1:
+			tokens = append(tokens, s[i:i+1])
+			i++
+		case c == '<' || c == '>':
+			tok := s[i : i+1]
+			if i+1 < len(s) && (s[i+1] == '=' || (c == '<' && s[i+1] == '>')) {
+				tok = s[i : i+2]
+			}
+			tokens = append(tokens, tok)
+			i += len(tok)
+		default:
+			tok := reExprToken.FindString(s[i:])
+			if tok == "" {
+				return nil, errs.Errorf("%v: unexpected character '%c'", ErrInvalidCondition, c)
+			}
+			tokens = append(tokens, tok)
+			i += len(tok)
+		}
+	}
+	return tokens, nil
+}
+
+type condParser struct {
+	tokens   []string
+	pos      int
+	valueSub Item
+	nameSub  map[string]*string
+}
+
+func (p *condParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *condParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *condParser) isKeyword(keyword string) bool {
+	return strings.EqualFold(p.peek(), keyword)
+}
+
+func (p *condParser) expect(tok string) error {
+	if got := p.next(); !strings.EqualFold(got, tok) {
+		return errs.Errorf("%v: expected '%s', got '%s'", ErrInvalidCondition, tok, got)
+	}
+	return nil
+}
+
+func (p *condParser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.isKeyword("OR") {
+		p.next()
+		var right condition
+		if right, err = p.parseAnd(); err == nil {
+			left = &orCond{left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *condParser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	for err == nil && p.isKeyword("AND") {
+		p.next()
+		var right condition
+		if right, err = p.parseNot(); err == nil {
+			left = &andCond{left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *condParser) parseNot() (condition, error) {
+	if !p.isKeyword("NOT") {
+		return p.parsePrimary()
+	}
+	p.next()
+	c, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	return &notCond{cond: c}, nil
+}
+
+var condFuncs = map[string]bool{
+	"attribute_exists":     true,
+	"attribute_not_exists": true,
+	"attribute_type":       true,
+	"begins_with":          true,
+	"contains":             true,
+}
+
+func (p *condParser) parsePrimary() (condition, error) {
+	if p.peek() == "(" {
+		p.next()
+		c, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return c, p.expect(")")
+	}
+	if condFuncs[p.peek()] {
+		return p.parseFunc()
+	}
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := p.next(); {
+	case strings.EqualFold(tok, "BETWEEN"):
+		return p.parseBetween(left)
+	case strings.EqualFold(tok, "IN"):
+		return p.parseIn(left)
+	default:
+		o, ok := parseComparator(tok)
+		if !ok {
+			return nil, errs.Errorf("%v: expected comparator, got '%s'", ErrInvalidCondition, tok)
+		}
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &cmpCond{op: o, left: left, right: right}, nil
+	}
+}
+
+// parseComparator returns the comparison op written as tok.
+func parseComparator(tok string) (op, bool) {
+	for _, o := range []op{eq, notEq, less, lessEq, greater, greaterEq} {
+		if tok == o.String() {
+			return o, true
+		}
+	}
+	return 0, false
+}
+
+func (p *condParser) parseFunc() (condition, error) {
+	name := p.next()
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	c := &funcCond{name: name, path: path}
+	if name != "attribute_exists" && name != "attribute_not_exists" {
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		if c.arg, err = p.parseOperand(); err != nil {
+			return nil, err
+		}
+	}
+	return c, p.expect(")")
+}
+
+func (p *condParser) parseBetween(val *operand) (condition, error) {
+	low, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("AND"); err != nil {
+		return nil, err
+	}
+	high, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return &betweenCond{val: val, low: low, high: high}, nil
+}
+
+func (p *condParser) parseIn(val *operand) (condition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	c := &inCond{val: val}
+	for {
+		o, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		c.list = append(c.list, o)
+		if p.peek() != "," {
+			break
+		}
+		p.next()
+	}
+	return c, p.expect(")")
+}
+
+func (p *condParser) parseOperand() (*operand, error) {
+	tok := p.peek()
+	if reExprVal.MatchString(tok) {
+		p.next()
+		av, ok := p.valueSub[tok]
+		if !ok {
+			return nil, errs.Errorf("%v: %v: %s", ErrInvalidCondition, ErrSubstitution, tok)
+		}
+		return &operand{val: av}, nil
+	}
+	if tok == "size" && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1] == "(" {
+		p.next()
+		p.next()
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return &operand{path: path, size: true}, p.expect(")")
+	}
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	return &operand{path: path}, nil
+}
+
+func (p *condParser) parsePath() (attrPath, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	path := attrPath{{name: name}}
+	for {
+		switch p.peek() {
+		case ".":
+			p.next()
+			if name, err = p.parseName(); err != nil {
+				return nil, err
+			}
+			path = append(path, pathElem{name: name})
+		case "[":
+			p.next()
+			tok := p.next()
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 {
+				return nil, errs.Errorf("%v: invalid list index '%s'", ErrInvalidCondition, tok)
+			}
+			path = append(path, pathElem{index: i})
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+		default:
+			return path, nil
+		}
+	}
+}
+
+func (p *condParser) parseName() (string, error) {
+	tok := p.next()
+	if !reExprName.MatchString(tok) {
+		return "", errs.Errorf("%v: invalid expression attribute name '%s'", ErrInvalidCondition, tok)
+	}
+	return substituteName(tok, p.nameSub)
+}
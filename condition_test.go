@@ -0,0 +1,192 @@
+package dynamock
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func conditionItem() Item {
+	return Item{
+		"id":      {S: strPtr("1")},
+		"name":    {S: strPtr("red pen")},
+		"price":   {N: strPtr("11")},
+		"data":    {B: []byte{1, 2, 3}},
+		"tags":    strSet("office", "red"),
+		"sizes":   numSet("1", "2", "3"),
+		"hash":    {BS: [][]byte{{1}, {2}}},
+		"ok":      {BOOL: boolPtr(true)},
+		"nothing": {NULL: boolPtr(true)},
+		"parts":   {L: []*dynamodb.AttributeValue{{S: strPtr("cap")}, {M: Item{"n": {N: strPtr("2")}}}}},
+		"info":    {M: Item{"color": {S: strPtr("red")}, "dims": {L: []*dynamodb.AttributeValue{{N: strPtr("10")}}}}},
+	}
+}
+
+func TestConditionEval(t *testing.T) {
+	valueSub := Item{
+		":id":     {S: strPtr("1")},
+		":red":    {S: strPtr("red")},
+		":pen":    {S: strPtr("pen")},
+		":p":      {S: strPtr("red ")},
+		":office": {S: strPtr("office")},
+		":cap":    {S: strPtr("cap")},
+		":two":    {N: strPtr("2.0")},
+		":three":  {N: strPtr("3")},
+		":ten":    {N: strPtr("10")},
+		":eleven": {N: strPtr("11.00")},
+		":twenty": {N: strPtr("20")},
+		":b":      {B: []byte{1, 2}},
+		":b1":     {B: []byte{1}},
+		":tags":   strSet("red", "office"),
+		":SS":     {S: strPtr("SS")},
+		":true":   {BOOL: boolPtr(true)},
+		":S":      {S: strPtr("S")},
+		":N":      {S: strPtr("N")},
+		":B":      {S: strPtr("B")},
+		":BOOL":   {S: strPtr("BOOL")},
+		":NULL":   {S: strPtr("NULL")},
+		":L":      {S: strPtr("L")},
+		":M":      {S: strPtr("M")},
+		":BS":     {S: strPtr("BS")},
+	}
+	nameSub := map[string]*string{"#n": strPtr("name"), "#i": strPtr("info")}
+	testCases := map[string]bool{
+		"id = :id":                                true,
+		"id <> :id":                               false,
+		"price = :eleven":                         true,
+		"price > :ten AND price < :twenty":        true,
+		"price >= :eleven AND price <= :ten":      false,
+		"price < :id":                             false,
+		"missing = :id":                           false,
+		"missing <> :id":                          false,
+		"price BETWEEN :ten AND :twenty":          true,
+		"price between :ten and :eleven":          true,
+		"price BETWEEN :twenty AND :ten":          false,
+		"price BETWEEN :id AND :twenty":           false,
+		"missing BETWEEN :ten AND :twenty":        false,
+		"price IN (:ten, :eleven)":                true,
+		"price IN (:ten)":                         false,
+		"missing IN (:ten)":                       false,
+		"tags = :tags":                            true,
+		"tags <> :tags":                           false,
+		"ok = :true":                              true,
+		"attribute_exists(price)":                 true,
+		"attribute_exists(missing)":               false,
+		"attribute_not_exists(missing)":           true,
+		"attribute_exists(#i.color)":              true,
+		"attribute_exists(#i.dims[0])":            true,
+		"attribute_exists(#i.dims[1])":            false,
+		"attribute_exists(missing.a[0])":          false,
+		"attribute_type(tags, :SS)":               true,
+		"attribute_type(id, :S)":                  true,
+		"attribute_type(price, :N)":               true,
+		"attribute_type(data, :B)":                true,
+		"attribute_type(ok, :BOOL)":               true,
+		"attribute_type(nothing, :NULL)":          true,
+		"attribute_type(parts, :L)":               true,
+		"attribute_type(#i, :M)":                  true,
+		"attribute_type(hash, :BS)":               true,
+		"id < :red":                               true,
+		"data > :b1":                              true,
+		"attribute_type(sizes, :SS)":              false,
+		"attribute_type(tags, :two)":              false,
+		"attribute_type(missing, :SS)":            false,
+		"begins_with(#n, :p)":                     true,
+		"begins_with(#n, :pen)":                   false,
+		"begins_with(data, :b)":                   true,
+		"begins_with(price, :b)":                  false,
+		"contains(#n, :pen)":                      true,
+		"contains(tags, :office)":                 true,
+		"contains(tags, :pen)":                    false,
+		"contains(sizes, :two)":                   true,
+		"contains(hash, :b1)":                     true,
+		"contains(parts, :cap)":                   true,
+		"contains(parts, :pen)":                   false,
+		"contains(price, :ten)":                   false,
+		"size(tags) = :two":                       true,
+		"size(sizes) = :three":                    true,
+		"size(#n) > :three":                       true,
+		"size(data) = :three":                     true,
+		"size(parts) = :two":                      true,
+		"size(#i) = :two":                         true,
+		"size(price) = :two":                      false,
+		"size(missing) = :two":                    false,
+		"parts[1].n = :two":                       true,
+		"NOT id = :id":                            false,
+		"id = :red OR price = :ten OR ok = :true": true,
+		"(id = :red OR price = :eleven) AND NOT (contains(tags, :pen))": true,
+		"id = :red AND price = :eleven OR ok = :true":                   true,
+	}
+	item := conditionItem()
+	for s, want := range testCases {
+		s, want := s, want
+		t.Run(s, func(t *testing.T) {
+			c, err := parseCondition(&s, valueSub, nameSub)
+			require.NoError(t, err)
+			require.Equal(t, want, c.eval(item))
+		})
+	}
+}
+
+func TestParseConditionNil(t *testing.T) {
+	c, err := parseCondition(nil, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, c)
+	require.NoError(t, checkCondition(c, nil))
+	items := []Item{conditionItem()}
+	require.Equal(t, items, filterItems(items, c))
+}
+
+func TestCheckCondition(t *testing.T) {
+	c, err := parseCondition(strPtr("attribute_not_exists(id)"), nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, checkCondition(c, nil))
+	err = checkCondition(c, conditionItem())
+	requireErrIs(t, err, ErrConditionalCheckFailed)
+	require.Equal(t, []Item{}, filterItems([]Item{conditionItem()}, c))
+}
+
+func TestParseConditionErr(t *testing.T) {
+	valueSub := Item{":v": {S: strPtr("1")}}
+	testCases := map[string]error{
+		"":                        ErrInvalidCondition,
+		"= :v":                    ErrInvalidCondition,
+		"a b :v":                  ErrInvalidCondition,
+		"a = :v b":                ErrInvalidCondition,
+		"a = :v AND":              ErrInvalidCondition,
+		"a = :v OR":               ErrInvalidCondition,
+		"NOT":                     ErrInvalidCondition,
+		"a ! :v":                  ErrInvalidCondition,
+		"a == :v":                 ErrInvalidCondition,
+		"a = ":                    ErrInvalidCondition,
+		"a = :missing":            ErrSubstitution,
+		"#a = :v":                 ErrSubstitution,
+		"(a = :v":                 ErrInvalidCondition,
+		"(a = ":                   ErrInvalidCondition,
+		"a BETWEEN :v":            ErrInvalidCondition,
+		"a BETWEEN :x AND :v":     ErrSubstitution,
+		"a BETWEEN :v AND :x":     ErrSubstitution,
+		"a IN :v":                 ErrInvalidCondition,
+		"a IN (:v, :x)":           ErrSubstitution,
+		"a IN (:v":                ErrInvalidCondition,
+		"contains":                ErrInvalidCondition,
+		"contains(":               ErrInvalidCondition,
+		"contains(a)":             ErrInvalidCondition,
+		"contains(a, :x)":         ErrSubstitution,
+		"attribute_exists(a, :v)": ErrInvalidCondition,
+		"size(a = :v":             ErrInvalidCondition,
+		"size(:v) = :v":           ErrInvalidCondition,
+		"a.:v = :v":               ErrInvalidCondition,
+		"a[x] = :v":               ErrInvalidCondition,
+		"a[-1] = :v":              ErrInvalidCondition,
+		"a[1 = :v":                ErrInvalidCondition,
+	}
+	for s, want := range testCases {
+		s, want := s, want
+		t.Run(s, func(t *testing.T) {
+			_, err := parseCondition(&s, valueSub, nil)
+			requireErrIs(t, err, want)
+		})
+	}
+}
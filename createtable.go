@@ -0,0 +1,158 @@
+package dynamock
+
+import (
+	"encoding/json"
+	"io"
+
+	"foxygo.at/s/errs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+var keyTypes = map[string]string{
+	dynamodb.ScalarAttributeTypeS: "string",
+	dynamodb.ScalarAttributeTypeN: "number",
+	dynamodb.ScalarAttributeTypeB: "binary",
+}
+
+var attrTypes = map[string]string{
+	"string": dynamodb.ScalarAttributeTypeS,
+	"number": dynamodb.ScalarAttributeTypeN,
+	"binary": dynamodb.ScalarAttributeTypeB,
+}
+
+// ReadCreateTableInput reads CreateTable JSON as used by
+// `aws dynamodb create-table --cli-input-json`.
+func ReadCreateTableInput(r io.Reader) (*dynamodb.CreateTableInput, error) {
+	in := &dynamodb.CreateTableInput{}
+	if err := json.NewDecoder(r).Decode(in); err != nil {
+		return nil, errs.Errorf("%v: CreateTable JSON: %v", ErrSchemaValidation, err)
+	}
+	return in, nil
+}
+
+// SchemaFromCreateTableInput converts the KeySchema, AttributeDefinitions,
+// GlobalSecondaryIndexes, LocalSecondaryIndexes and StreamSpecification of
+// in to a Schema.
+func SchemaFromCreateTableInput(in *dynamodb.CreateTableInput) (Schema, error) {
+	types := map[string]string{}
+	for _, ad := range in.AttributeDefinitions {
+		if ad.AttributeName == nil || ad.AttributeType == nil {
+			return Schema{}, errs.Errorf("%v: %v: AttributeDefinition", ErrSchemaValidation, ErrNil)
+		}
+		t, ok := keyTypes[*ad.AttributeType]
+		if !ok {
+			return Schema{}, errs.Errorf("%v: %v: '%s' for attribute '%s'", ErrSchemaValidation, ErrUnknownType, *ad.AttributeType, *ad.AttributeName)
+		}
+		types[*ad.AttributeName] = t
+	}
+	pk, err := keyDefFromKeySchema(in.KeySchema, types)
+	if err != nil {
+		return Schema{}, errs.Errorf("%v (KeySchema)", err)
+	}
+	schema := Schema{PrimaryKey: pk}
+	for _, gsi := range in.GlobalSecondaryIndexes {
+		k, err := keyDefFromKeySchema(gsi.KeySchema, types)
+		if err != nil {
+			return Schema{}, errs.Errorf("%v (GlobalSecondaryIndex %s)", err, aws.StringValue(gsi.IndexName))
+		}
+		k.Name = aws.StringValue(gsi.IndexName)
+		schema.GSIs = append(schema.GSIs, k)
+	}
+	for _, lsi := range in.LocalSecondaryIndexes {
+		k, err := keyDefFromKeySchema(lsi.KeySchema, types)
+		if err != nil {
+			return Schema{}, errs.Errorf("%v (LocalSecondaryIndex %s)", err, aws.StringValue(lsi.IndexName))
+		}
+		k.Name = aws.StringValue(lsi.IndexName)
+		schema.LSIs = append(schema.LSIs, k)
+	}
+	if ss := in.StreamSpecification; ss != nil && aws.BoolValue(ss.StreamEnabled) {
+		schema.StreamViewType = aws.StringValue(ss.StreamViewType)
+	}
+	return schema, nil
+}
+
+func keyDefFromKeySchema(keySchema []*dynamodb.KeySchemaElement, types map[string]string) (KeyDef, error) {
+	k := KeyDef{}
+	for _, e := range keySchema {
+		name := aws.StringValue(e.AttributeName)
+		t, ok := types[name]
+		if !ok {
+			return KeyDef{}, errs.Errorf("%v: %v: no AttributeDefinition for '%s'", ErrSchemaValidation, ErrMissingType, name)
+		}
+		switch aws.StringValue(e.KeyType) {
+		case dynamodb.KeyTypeHash:
+			if k.PartitionKey.Name != "" {
+				return KeyDef{}, errs.Errorf("%v: %v: HASH key '%s'", ErrSchemaValidation, ErrDuplicate, name)
+			}
+			k.PartitionKey = KeyPartDef{Name: name, Type: t}
+		case dynamodb.KeyTypeRange:
+			if k.SortKey != nil {
+				return KeyDef{}, errs.Errorf("%v: %v: RANGE key '%s'", ErrSchemaValidation, ErrDuplicate, name)
+			}
+			k.SortKey = &KeyPartDef{Name: name, Type: t}
+		default:
+			return KeyDef{}, errs.Errorf("%v: %v: KeyType '%s'", ErrSchemaValidation, ErrUnknownType, aws.StringValue(e.KeyType))
+		}
+	}
+	if k.PartitionKey.Name == "" {
+		return KeyDef{}, errs.Errorf("%v: %v: HASH key", ErrSchemaValidation, ErrMissingName)
+	}
+	return k, nil
+}
+
+// CreateTableInput returns the CreateTable request for a table with the
+// given name and schema. All indexes project all attributes as dynamock
+// does not support projections, billing mode is PAY_PER_REQUEST.
+func (s Schema) CreateTableInput(tableName string) *dynamodb.CreateTableInput {
+	in := &dynamodb.CreateTableInput{
+		TableName:   aws.String(tableName),
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+	}
+	defined := map[string]bool{}
+	addKeyDef := func(k KeyDef) []*dynamodb.KeySchemaElement {
+		var keySchema []*dynamodb.KeySchemaElement
+		for i, kp := range keyParts(k) {
+			keyType := dynamodb.KeyTypeHash
+			if i == 1 {
+				keyType = dynamodb.KeyTypeRange
+			}
+			keySchema = append(keySchema, &dynamodb.KeySchemaElement{
+				AttributeName: aws.String(kp.Name),
+				KeyType:       aws.String(keyType),
+			})
+			if !defined[kp.Name] {
+				defined[kp.Name] = true
+				in.AttributeDefinitions = append(in.AttributeDefinitions, &dynamodb.AttributeDefinition{
+					AttributeName: aws.String(kp.Name),
+					AttributeType: aws.String(attrTypes[kp.Type]),
+				})
+			}
+		}
+		return keySchema
+	}
+	in.KeySchema = addKeyDef(s.PrimaryKey)
+	projection := &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)}
+	for _, gsi := range s.GSIs {
+		in.GlobalSecondaryIndexes = append(in.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndex{
+			IndexName:  aws.String(gsi.Name),
+			KeySchema:  addKeyDef(gsi),
+			Projection: projection,
+		})
+	}
+	for _, lsi := range s.LSIs {
+		in.LocalSecondaryIndexes = append(in.LocalSecondaryIndexes, &dynamodb.LocalSecondaryIndex{
+			IndexName:  aws.String(lsi.Name),
+			KeySchema:  addKeyDef(lsi),
+			Projection: projection,
+		})
+	}
+	if s.StreamViewType != "" {
+		in.StreamSpecification = &dynamodb.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: aws.String(s.StreamViewType),
+		}
+	}
+	return in
+}
@@ -0,0 +1,203 @@
+package dynamock
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func orderSchema() Schema {
+	return Schema{
+		PrimaryKey: KeyDef{
+			PartitionKey: KeyPartDef{Name: "customer", Type: "string"},
+			SortKey:      &KeyPartDef{Name: "orderId", Type: "number"},
+		},
+		GSIs: []KeyDef{{Name: "statusGSI", PartitionKey: KeyPartDef{Name: "status", Type: "string"}}},
+		LSIs: []KeyDef{{
+			Name:         "totalLSI",
+			PartitionKey: KeyPartDef{Name: "customer", Type: "string"},
+			SortKey:      &KeyPartDef{Name: "total", Type: "number"},
+		}},
+	}
+}
+
+func readCreateTableInput(t *testing.T, filename string) *dynamodb.CreateTableInput {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", filename))
+	require.NoError(t, err)
+	defer f.Close()
+	in, err := ReadCreateTableInput(f)
+	require.NoError(t, err)
+	return in
+}
+
+func TestSchemaFromCreateTableInput(t *testing.T) {
+	in := readCreateTableInput(t, "create-table-order.json")
+	got, err := SchemaFromCreateTableInput(in)
+	require.NoError(t, err)
+	require.Equal(t, orderSchema(), got)
+}
+
+func TestSchemaCreateTableInput(t *testing.T) {
+	got := orderSchema().CreateTableInput("order")
+	want := readCreateTableInput(t, "create-table-order.json")
+	require.Equal(t, want, got)
+
+	schema, err := SchemaFromCreateTableInput(got)
+	require.NoError(t, err)
+	require.Equal(t, orderSchema(), schema)
+
+	binSchema := Schema{PrimaryKey: KeyDef{PartitionKey: KeyPartDef{Name: "id", Type: "binary"}}}
+	got = binSchema.CreateTableInput("bin")
+	require.Equal(t, "B", *got.AttributeDefinitions[0].AttributeType)
+	require.Nil(t, got.GlobalSecondaryIndexes)
+}
+
+func TestReadCreateTableInputErr(t *testing.T) {
+	_, err := ReadCreateTableInput(strings.NewReader("{"))
+	requireErrIs(t, err, ErrSchemaValidation)
+}
+
+func TestSchemaFromCreateTableInputErr(t *testing.T) {
+	ad := func(name, typ string) *dynamodb.AttributeDefinition {
+		return &dynamodb.AttributeDefinition{AttributeName: strPtr(name), AttributeType: strPtr(typ)}
+	}
+	ks := func(name, typ string) *dynamodb.KeySchemaElement {
+		return &dynamodb.KeySchemaElement{AttributeName: strPtr(name), KeyType: strPtr(typ)}
+	}
+	ads := []*dynamodb.AttributeDefinition{ad("id", "S"), ad("n", "N")}
+	testCases := map[string]struct {
+		in   *dynamodb.CreateTableInput
+		want error
+	}{
+		"nil_attr_def": {
+			in:   &dynamodb.CreateTableInput{AttributeDefinitions: []*dynamodb.AttributeDefinition{{}}},
+			want: ErrNil,
+		},
+		"bad_attr_type": {
+			in:   &dynamodb.CreateTableInput{AttributeDefinitions: []*dynamodb.AttributeDefinition{ad("id", "SS")}},
+			want: ErrUnknownType,
+		},
+		"missing_hash": {
+			in:   &dynamodb.CreateTableInput{AttributeDefinitions: ads},
+			want: ErrMissingName,
+		},
+		"undefined_attr": {
+			in:   &dynamodb.CreateTableInput{AttributeDefinitions: ads, KeySchema: []*dynamodb.KeySchemaElement{ks("x", "HASH")}},
+			want: ErrMissingType,
+		},
+		"bad_key_type": {
+			in:   &dynamodb.CreateTableInput{AttributeDefinitions: ads, KeySchema: []*dynamodb.KeySchemaElement{ks("id", "PRIMARY")}},
+			want: ErrUnknownType,
+		},
+		"two_hash": {
+			in:   &dynamodb.CreateTableInput{AttributeDefinitions: ads, KeySchema: []*dynamodb.KeySchemaElement{ks("id", "HASH"), ks("n", "HASH")}},
+			want: ErrDuplicate,
+		},
+		"two_range": {
+			in: &dynamodb.CreateTableInput{AttributeDefinitions: ads, KeySchema: []*dynamodb.KeySchemaElement{
+				ks("id", "HASH"), ks("n", "RANGE"), ks("n", "RANGE"),
+			}},
+			want: ErrDuplicate,
+		},
+		"gsi": {
+			in: &dynamodb.CreateTableInput{
+				AttributeDefinitions:   ads,
+				KeySchema:              []*dynamodb.KeySchemaElement{ks("id", "HASH")},
+				GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{{IndexName: strPtr("g"), KeySchema: []*dynamodb.KeySchemaElement{ks("x", "HASH")}}},
+			},
+			want: ErrMissingType,
+		},
+		"lsi": {
+			in: &dynamodb.CreateTableInput{
+				AttributeDefinitions:  ads,
+				KeySchema:             []*dynamodb.KeySchemaElement{ks("id", "HASH")},
+				LocalSecondaryIndexes: []*dynamodb.LocalSecondaryIndex{{IndexName: strPtr("l"), KeySchema: []*dynamodb.KeySchemaElement{ks("n", "RANGE")}}},
+			},
+			want: ErrMissingName,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := SchemaFromCreateTableInput(tc.in)
+			requireErrIs(t, err, ErrSchemaValidation)
+			requireErrIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestDBFromReaderCreateTable(t *testing.T) {
+	db := ReadTestdataDB(t, "createtable.json")
+	ot := db.tables["order"]
+	require.Equal(t, orderSchema().LSIs, ot.schema.LSIs)
+
+	in := &dynamodb.QueryInput{
+		TableName:                 strPtr("order"),
+		IndexName:                 strPtr("totalLSI"),
+		KeyConditionExpression:    strPtr("customer = :c AND total > :t"),
+		ExpressionAttributeValues: Item{":c": {S: strPtr("ann")}, ":t": {N: strPtr("15")}},
+	}
+	out, err := db.Query(in)
+	require.NoError(t, err)
+	require.Len(t, out.Items, 2)
+	require.Equal(t, "3", *out.Items[0]["orderId"].N)
+	require.Equal(t, "1", *out.Items[1]["orderId"].N)
+
+	sb := &bytes.Buffer{}
+	require.NoError(t, db.WriteSnap(sb))
+	require.Contains(t, sb.String(), `"createTable": "testdata/create-table-order.json"`)
+	db2, err := NewDBFromReader(sb)
+	require.NoError(t, err)
+	require.Equal(t, ot.schema.LSIs, db2.tables["order"].schema.LSIs)
+}
+
+func TestDBFromReaderCreateTableName(t *testing.T) {
+	r := strings.NewReader(`{"tables": [{"createTable": "testdata/create-table-order.json", "items": []}]}`)
+	db, err := NewDBFromReader(r)
+	require.NoError(t, err)
+	require.Equal(t, []string{"order"}, db.tableNames)
+
+	abs, err := filepath.Abs(filepath.Join("testdata", "create-table-order.json"))
+	require.NoError(t, err)
+	db, err = newDBFromJSON(&JSONDB{Tables: []*JSONTable{{Name: "o", CreateTable: abs}}}, "other")
+	require.NoError(t, err)
+	require.Equal(t, []string{"o"}, db.tableNames)
+}
+
+func TestDBFromReaderCreateTableErr(t *testing.T) {
+	testCases := map[string]struct {
+		table string
+		want  error
+	}{
+		"schema_and_create_table": {
+			table: `"schema": {"primaryKey": {"partitionKey": {"name": "id", "type": "string"}}}, "createTable": "testdata/create-table-order.json"`,
+			want:  ErrSchemaValidation,
+		},
+		"missing_file": {
+			table: `"createTable": "testdata/MISSING.json"`,
+			want:  ErrSchemaValidation,
+		},
+		"bad_json": {
+			table: `"createTable": "testdata/db.json"`,
+			want:  ErrMissingName,
+		},
+		"not_json": {
+			table: `"createTable": "testdata/export/data/a1b2c3d4e5f6g7h8i9j0k1l2m3.json.gz"`,
+			want:  ErrSchemaValidation,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			r := strings.NewReader(`{"tables": [{"name": "t", ` + tc.table + `, "items": []}]}`)
+			_, err := NewDBFromReader(r)
+			requireErrIs(t, err, tc.want)
+		})
+	}
+}
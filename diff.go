@@ -0,0 +1,214 @@
+package dynamock
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// DBDiff holds the differences between the tables of two DBs, see Diff.
+type DBDiff struct {
+	Tables []*TableDiff
+}
+
+// TableDiff holds the items of a table that were added, removed or
+// modified. All items of a table that exists in only one of the DBs are
+// added or removed.
+type TableDiff struct {
+	Name     string
+	Key      KeyDef // primary key items are matched by
+	Added    []Item
+	Removed  []Item
+	Modified []*ItemDiff
+}
+
+// ItemDiff holds the changed attributes of the item with primary key Key.
+type ItemDiff struct {
+	Key     Item
+	Changes []*AttrChange // sorted by name
+}
+
+// AttrChange is a changed attribute. Old is nil for an added attribute
+// and New is nil for a removed attribute.
+type AttrChange struct {
+	Name string
+	Old  *dynamodb.AttributeValue
+	New  *dynamodb.AttributeValue
+}
+
+// Diff compares the tables of from and to by name and their items by
+// primary key. If the primary key of a table changed, items are matched
+// by the primary key in to. Attributes are compared by value, e.g.
+// numbers numerically and sets irrespective of member order. Unchanged
+// tables are omitted.
+func Diff(from, to *DB) *DBDiff {
+	fromTables := map[string]*Table{}
+	var names []string
+	for _, t := range from.tableList() {
+		fromTables[t.name] = t
+		names = append(names, t.name)
+	}
+	toTables := map[string]*Table{}
+	for _, t := range to.tableList() {
+		toTables[t.name] = t
+		if fromTables[t.name] == nil {
+			names = append(names, t.name)
+		}
+	}
+	d := &DBDiff{}
+	for _, name := range names {
+		if td := diffTable(name, fromTables[name], toTables[name]); !td.empty() {
+			d.Tables = append(d.Tables, td)
+		}
+	}
+	return d
+}
+
+// DiffFixture compares the fixture file or table directory layout at
+// path, see OpenDB, as from with db as to, see Diff.
+func DiffFixture(path string, db *DB) (*DBDiff, error) {
+	from, err := newDBFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(from, db), nil
+}
+
+func diffTable(name string, from, to *Table) *TableDiff {
+	fromItems, key := tableItems(from)
+	toItems, toKey := tableItems(to)
+	if to != nil {
+		key = toKey
+	}
+	td := &TableDiff{Name: name, Key: key}
+	byKey := map[keyStrings]Item{}
+	for _, item := range fromItems {
+		if k, err := getKeyStrings(item, key); err == nil {
+			byKey[*k] = item
+		}
+	}
+	matched := map[keyStrings]bool{}
+	for _, item := range toItems {
+		k, _ := getKeyStrings(item, key)
+		old, ok := byKey[*k]
+		if !ok {
+			td.Added = append(td.Added, item)
+			continue
+		}
+		matched[*k] = true
+		if changes := diffItem(old, item); len(changes) != 0 {
+			td.Modified = append(td.Modified, &ItemDiff{Key: primaryKey(item, key), Changes: changes})
+		}
+	}
+	for _, item := range fromItems {
+		if k, err := getKeyStrings(item, key); err != nil || !matched[*k] {
+			td.Removed = append(td.Removed, item)
+		}
+	}
+	return td
+}
+
+// tableItems returns the items and primary key of t, which may be nil.
+func tableItems(t *Table) ([]Item, KeyDef) {
+	if t == nil {
+		return nil, KeyDef{}
+	}
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return t.items, t.schema.PrimaryKey
+}
+
+func diffItem(from, to Item) []*AttrChange {
+	var names []string
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if from[name] == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var changes []*AttrChange
+	for _, name := range names {
+		if !attrEqual(from[name], to[name]) {
+			changes = append(changes, &AttrChange{Name: name, Old: from[name], New: to[name]})
+		}
+	}
+	return changes
+}
+
+func (td *TableDiff) empty() bool {
+	return len(td.Added) == 0 && len(td.Removed) == 0 && len(td.Modified) == 0
+}
+
+// Empty reports whether the compared DBs are equal.
+func (d *DBDiff) Empty() bool {
+	return len(d.Tables) == 0
+}
+
+// WriteText writes the differences of every table in the column aligned
+// format of WriteSnap, one row per item for removed (-) and added (+)
+// items and one row per changed attribute for modified (~) items:
+//
+//	table product
+//	op, id, attribute,                   old,                   new
+//	 -,  2,          , {"id":"2","name":"x"},                 <nil>
+//	 ~,  1,     price,                    11,                    12
+//	 +,  9,          ,                 <nil>, {"id":"9","name":"y"}
+func (d *DBDiff) WriteText(w io.Writer) error {
+	for i, td := range d.Tables {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "table %s\n", td.Name)
+		var keyCols []string
+		for _, k := range keyParts(td.Key) {
+			keyCols = append(keyCols, k.Name)
+		}
+		cols := append(append([]string{"op"}, keyCols...), "attribute", "old", "new")
+		var rows [][]interface{}
+		addRow := func(op string, key Item, attr string, old, newItem interface{}) {
+			row := []interface{}{op}
+			for _, k := range keyCols {
+				row = append(row, attrInterface(key[k]))
+			}
+			// padding applies to the members of lists, so pad strings
+			rows = append(rows, append(row, attr, fmt.Sprint(old), fmt.Sprint(newItem)))
+		}
+		for _, item := range td.Removed {
+			addRow("-", item, "", ItemToJSON(item), nil)
+		}
+		for _, id := range td.Modified {
+			for _, c := range id.Changes {
+				addRow("~", id.Key, c.Name, attrInterface(c.Old), attrInterface(c.New))
+			}
+		}
+		for _, item := range td.Added {
+			addRow("+", item, "", nil, ItemToJSON(item))
+		}
+		writeRows(w, cols, rows)
+	}
+	return nil
+}
+
+func (d *DBDiff) String() string {
+	sb := &bytes.Buffer{}
+	_ = d.WriteText(sb)
+	return sb.String()
+}
+
+// attrInterface returns av as plain Go value as printed by WriteSnap, or
+// nil if av is nil.
+func attrInterface(av *dynamodb.AttributeValue) interface{} {
+	if av == nil {
+		return nil
+	}
+	var v interface{}
+	_ = dynamodbattribute.Unmarshal(av, &v)
+	return v
+}
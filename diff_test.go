@@ -0,0 +1,97 @@
+package dynamock
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	require.True(t, Diff(db, db.Clone()).Empty())
+
+	clone := db.Clone()
+	require.NoError(t, putProduct(t, clone, "9"))
+	_, err := clone.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 strPtr("product"),
+		Key:                       Item{"id": {S: strPtr("1")}},
+		UpdateExpression:          strPtr("SET price = :p, tags = :t REMOVE #n"),
+		ExpressionAttributeValues: Item{":p": {N: strPtr("12")}, ":t": {SS: []*string{strPtr("a")}}},
+		ExpressionAttributeNames:  map[string]*string{"#n": strPtr("name")},
+	})
+	require.NoError(t, err)
+	_, err = clone.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 strPtr("product"),
+		Key:                       Item{"id": {S: strPtr("2")}},
+		UpdateExpression:          strPtr("SET price = :p"),
+		ExpressionAttributeValues: Item{":p": {N: strPtr("22.0")}},
+	})
+	require.NoError(t, err)
+	_, err = clone.DeleteItem(&dynamodb.DeleteItemInput{TableName: strPtr("path"), Key: Item{"folder": {S: strPtr("/Users/dev/")}, "file": {S: strPtr("todo.txt")}}})
+	require.NoError(t, err)
+
+	d := Diff(db, clone)
+	require.Len(t, d.Tables, 2)
+	product := d.Tables[0]
+	require.Equal(t, "product", product.Name)
+	require.Len(t, product.Added, 1)
+	require.Empty(t, product.Removed)
+	require.Len(t, product.Modified, 1) // 22.0 equals 22
+	require.Equal(t, Item{"id": {S: strPtr("1")}}, product.Modified[0].Key)
+	want := `
+table product
+op, id, attribute,     old,                       new
+ ~,  1,      name, red pen,                     <nil>
+ ~,  1,     price,      11,                        12
+ ~,  1,      tags,   <nil>,                       [a]
+ +,  9,          ,   <nil>, {"id":"9","name":"pen 9"}
+
+table path
+op,      folder,     file, attribute,                                                             old,   new
+ -, /Users/dev/, todo.txt,          , {"file":"todo.txt","folder":"/Users/dev/","perms":"-rw-r--r--"}, <nil>
+`[1:]
+	require.Equal(t, want, d.String())
+}
+
+func TestDiffTables(t *testing.T) {
+	from := ReadTestdataDB(t, "db.json")
+	to := NewDB()
+	product := &Table{
+		name:   "product",
+		schema: Schema{PrimaryKey: KeyDef{PartitionKey: KeyPartDef{Name: "sku", Type: "string"}}},
+		items:  []Item{{"sku": {S: strPtr("1")}}},
+	}
+	require.NoError(t, product.index())
+	require.NoError(t, to.addTable(product))
+	table := &Table{name: "t", schema: product.schema, items: []Item{{"sku": {S: strPtr("2")}}}}
+	require.NoError(t, table.index())
+	require.NoError(t, to.addTable(table))
+
+	d := Diff(from, to)
+	names := []string{}
+	for _, td := range d.Tables {
+		names = append(names, td.Name)
+	}
+	require.Equal(t, []string{"product", "person", "path", "t"}, names)
+	// items are matched by the changed primary key
+	require.Len(t, d.Tables[0].Removed, len(from.tables["product"].items))
+	require.Len(t, d.Tables[0].Added, 1)
+	require.Equal(t, "sku", d.Tables[0].Key.PartitionKey.Name)
+	require.Len(t, d.Tables[2].Removed, 2)
+	require.Equal(t, "file", d.Tables[2].Key.SortKey.Name)
+	require.Len(t, d.Tables[3].Added, 1)
+}
+
+func TestDiffFixture(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	require.NoError(t, putProduct(t, db, "9"))
+	d, err := DiffFixture(filepath.Join("testdata", "db.json"), db)
+	require.NoError(t, err)
+	require.Len(t, d.Tables, 1)
+	require.Len(t, d.Tables[0].Added, 1)
+
+	_, err = DiffFixture(filepath.Join("testdata", "MISSING.json"), db)
+	require.Error(t, err)
+}
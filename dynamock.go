@@ -8,8 +8,14 @@
 package dynamock
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"foxygo.at/s/errs"
 	"github.com/aws/aws-sdk-go/aws"
@@ -19,9 +25,12 @@ import (
 )
 
 type JSONTable struct {
-	Name   string                   `json:"name"`
-	Schema Schema                   `json:"schema"`
-	Items  []map[string]interface{} `json:"items"`
+	Name        string                   `json:"name"`
+	Schema      Schema                   `json:"schema"`
+	CreateTable string                   `json:"createTable,omitempty"` // CreateTable JSON file used instead of schema
+	Format      string                   `json:"format,omitempty"`      // json, dynamodb or empty to detect
+	Sets        map[string]string        `json:"sets,omitempty"`        // attribute name to member type: string, number, binary
+	Items       []map[string]interface{} `json:"items"`
 }
 
 type JSONDB struct {
@@ -32,32 +41,77 @@ type JSONDB struct {
 type DB struct {
 	UnimplementedDB
 
+	m          sync.RWMutex // guards tableNames, tables and persister, see Reload
 	tableNames []string
 	tables     map[string]*Table
 	pageSize   int
+	persister  *persister
+	faults     *faults
+	latency    *latency
+	clock      Clock
 }
 
 func NewDB() *DB {
 	return &DB{}
 }
 
+// NewDBFromReader reads a JSON fixture. Relative createTable paths are
+// resolved against the current working directory.
 func NewDBFromReader(r io.Reader) (*DB, error) {
-	jdb := JSONDB{}
-	if err := json.NewDecoder(r).Decode(&jdb); err != nil {
+	jdb, err := decodeJSONFixture(r)
+	if err != nil {
 		return nil, errs.Errorf("NewDBFromReader: %v", err)
 	}
+	return newDBFromJSON(jdb, "")
+}
+
+// NewDBFromFile reads a JSON fixture or, for the file extensions .yaml
+// and .yml, a YAML fixture. Relative createTable paths are resolved
+// against the directory of fname.
+func NewDBFromFile(fname string) (*DB, error) {
+	var decode func(io.Reader) (*JSONDB, error)
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".json":
+		decode = decodeJSONFixture
+	case ".yaml", ".yml":
+		decode = decodeYAMLFixture
+	default:
+		return nil, errs.Errorf("NewDBFromFile: %v: file extension of '%s'", ErrUnknownType, fname)
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, errs.Errorf("NewDBFromFile: %v", err)
+	}
+	defer f.Close()
+	jdb, err := decode(f)
+	if err != nil {
+		return nil, errs.Errorf("NewDBFromFile: %v (file: '%s')", err, fname)
+	}
+	return newDBFromJSON(jdb, filepath.Dir(fname))
+}
+
+func decodeJSONFixture(r io.Reader) (*JSONDB, error) {
+	jdb := &JSONDB{}
+	if err := json.NewDecoder(r).Decode(jdb); err != nil {
+		return nil, err
+	}
+	return jdb, nil
+}
+
+// newDBFromJSON creates a DB from a decoded fixture. dir is the directory
+// relative createTable paths are resolved against.
+func newDBFromJSON(jdb *JSONDB, dir string) (*DB, error) {
 	db := &DB{tables: map[string]*Table{}}
 	for _, t := range jdb.Tables {
-		items := make([]Item, len(t.Items))
-		for i, item := range t.Items {
-			av, _ := dynamodbattribute.MarshalMap(item)
-			items[i] = av
+		if err := t.prepare(dir); err != nil {
+			return nil, errs.Errorf("NewDBFromReader: %v (table: '%s')", err, t.Name)
 		}
-		table := &Table{name: t.Name, schema: t.Schema, items: items}
-		if err := validateTable(table); err != nil {
-			return nil, err
+		items, format, err := t.decodeItems(t.Items)
+		if err != nil {
+			return nil, errs.Errorf("NewDBFromReader: %v (table: '%s')", err, t.Name)
 		}
-		if err := table.index(); err != nil {
+		table, err := t.newTable(items, format)
+		if err != nil {
 			return nil, err
 		}
 		db.tableNames = append(db.tableNames, table.name)
@@ -66,22 +120,319 @@ func NewDBFromReader(r io.Reader) (*DB, error) {
 	return db, nil
 }
 
-func (db *DB) WriteSnap(w io.Writer) error {
-	jdb := JSONDB{
-		Tables: make([]*JSONTable, len(db.tables)),
+// prepare loads the createTable file of t and validates its sets and
+// format.
+func (t *JSONTable) prepare(dir string) error {
+	if err := t.loadCreateTable(dir); err != nil {
+		return err
+	}
+	for name, typ := range t.Sets {
+		if err := validateKeyType(typ); err != nil {
+			return errs.Errorf("set '%s': %v", name, err)
+		}
 	}
-	for i, name := range db.tableNames {
-		t := db.tables[name]
-		items := []map[string]interface{}{}
-		_ = dynamodbattribute.UnmarshalListOfMaps(t.items, &items)
-		jdb.Tables[i] = &JSONTable{Schema: t.schema, Name: t.name, Items: items}
+	return validateFormat(t.Format)
+}
+
+// decodeItems decodes fixture items in t.Format, or in the format
+// detected for items if t.Format is empty, and returns the format used.
+func (t *JSONTable) decodeItems(items []map[string]interface{}) ([]Item, string, error) {
+	format := t.Format
+	if format == "" {
+		format = detectFormat(items)
+	}
+	result := make([]Item, len(items))
+	for i, item := range items {
+		av, err := decodeItem(item, format, t)
+		if err != nil {
+			return nil, "", err
+		}
+		result[i] = av
+	}
+	return result, format, nil
+}
+
+// newTable creates a validated and indexed table with the definition of t.
+func (t *JSONTable) newTable(items []Item, format string) (*Table, error) {
+	table := &Table{name: t.Name, schema: t.Schema, createTable: t.CreateTable, format: format, items: items}
+	if err := validateTable(table); err != nil {
+		return nil, err
+	}
+	if err := table.index(); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// loadCreateTable sets t.Schema, and t.Name if empty, from the CreateTable
+// JSON file t.CreateTable.
+func (t *JSONTable) loadCreateTable(dir string) error {
+	if t.CreateTable == "" {
+		return nil
+	}
+	if t.Schema.PrimaryKey.PartitionKey.Name != "" {
+		return errs.Errorf("%v: schema and createTable are mutually exclusive", ErrSchemaValidation)
+	}
+	fname := t.CreateTable
+	if !filepath.IsAbs(fname) {
+		fname = filepath.Join(dir, fname)
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		return errs.Errorf("%v: createTable: %v", ErrSchemaValidation, err)
+	}
+	defer f.Close()
+	in, err := ReadCreateTableInput(f)
+	if err != nil {
+		return err
+	}
+	if t.Schema, err = SchemaFromCreateTableInput(in); err != nil {
+		return err
+	}
+	if t.Name == "" {
+		t.Name = aws.StringValue(in.TableName)
 	}
+	return nil
+}
+
+func decodeItem(item map[string]interface{}, format string, t *JSONTable) (Item, error) {
+	if format == formatDynamoDBJSON {
+		return decodeTypedItem(item)
+	}
+	av, _ := dynamodbattribute.MarshalMap(item)
+	if err := decodeBinaryKeys(av, t.Schema); err != nil {
+		return nil, err
+	}
+	if err := typeSets(av, t.Sets); err != nil {
+		return nil, err
+	}
+	return av, nil
+}
+
+// decodeBinaryKeys replaces the base64 encoded strings used for binary
+// key attributes in JSON with their decoded binary values.
+func decodeBinaryKeys(item Item, schema Schema) error {
+	keyDefs := append([]KeyDef{schema.PrimaryKey}, schema.secondaryIndexes()...)
+	for _, keyDef := range keyDefs {
+		for _, k := range keyParts(keyDef) {
+			if k.Type != "binary" || item[k.Name] == nil || item[k.Name].S == nil {
+				continue
+			}
+			b, err := base64.StdEncoding.DecodeString(*item[k.Name].S)
+			if err != nil {
+				return errs.Errorf("%v: %v: base64 value for '%s': %v", ErrItemValidation, ErrInvalidType, k.Name, err)
+			}
+			item[k.Name] = &dynamodb.AttributeValue{B: b}
+		}
+	}
+	return nil
+}
+
+// typeSets replaces the lists used for set attributes in JSON with string,
+// number or binary sets. sets maps attribute names to member types.
+func typeSets(item Item, sets map[string]string) error {
+	for name, typ := range sets {
+		attr := item[name]
+		if attr == nil {
+			continue
+		}
+		if attr.L == nil {
+			return errs.Errorf("%v: %v: set '%s': expected list", ErrItemValidation, ErrInvalidType, name)
+		}
+		set := &dynamodb.AttributeValue{}
+		switch typ {
+		case "string":
+			set.SS = []*string{}
+		case "number":
+			set.NS = []*string{}
+		case "binary":
+			set.BS = [][]byte{}
+		}
+		for _, m := range attr.L {
+			switch {
+			case typ == "string" && m.S != nil:
+				set.SS = append(set.SS, m.S)
+			case typ == "number" && m.N != nil:
+				set.NS = append(set.NS, m.N)
+			case typ == "binary" && m.S != nil:
+				b, err := base64.StdEncoding.DecodeString(*m.S)
+				if err != nil {
+					return errs.Errorf("%v: %v: base64 value in set '%s': %v", ErrItemValidation, ErrInvalidType, name, err)
+				}
+				set.BS = append(set.BS, b)
+			default:
+				return errs.Errorf("%v: %v: set '%s': expected %s members", ErrItemValidation, ErrInvalidType, name, typ)
+			}
+		}
+		item[name] = set
+	}
+	return nil
+}
+
+// setTypes returns the member types of all set attributes in items for
+// JSONTable.Sets. It returns false if the sets of items cannot be
+// described by JSONTable.Sets: if an attribute is a set in one item and of
+// another type in another item, or if a set is nested in a list or map.
+func setTypes(items []Item) (map[string]string, bool) {
+	types := map[string]string{} // set member type or "" for other types
+	for _, item := range items {
+		for name, attr := range item {
+			typ := setMemberType(attr)
+			if t, ok := types[name]; ok && t != typ {
+				return nil, false
+			}
+			types[name] = typ
+			if typ == "" && hasNestedSet(attr) {
+				return nil, false
+			}
+		}
+	}
+	sets := map[string]string{}
+	for name, typ := range types {
+		if typ != "" {
+			sets[name] = typ
+		}
+	}
+	return sets, true
+}
+
+// setMemberType returns the member type of a set attribute for
+// JSONTable.Sets or "" if attr is not a set.
+func setMemberType(attr *dynamodb.AttributeValue) string {
+	switch {
+	case attr.SS != nil:
+		return "string"
+	case attr.NS != nil:
+		return "number"
+	case attr.BS != nil:
+		return "binary"
+	}
+	return ""
+}
+
+// hasNestedSet reports whether the list or map attr contains a set.
+func hasNestedSet(attr *dynamodb.AttributeValue) bool {
+	for _, a := range attr.L {
+		if setMemberType(a) != "" || hasNestedSet(a) {
+			return true
+		}
+	}
+	for _, a := range attr.M {
+		if setMemberType(a) != "" || hasNestedSet(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteSnap writes all tables as JSON fixture that can be read with
+// NewDBFromReader. Each table is written in the format it was loaded from,
+// or in DynamoDB JSON format if its sets cannot be written in JSON format,
+// see JSONTable.Sets.
+func (db *DB) WriteSnap(w io.Writer) error {
+	return db.writeSnap(w, "")
+}
+
+// WriteTypedSnap writes all tables as JSON fixture with items in DynamoDB
+// JSON format, which round-trips all attribute types losslessly.
+func (db *DB) WriteTypedSnap(w io.Writer) error {
+	return db.writeSnap(w, formatDynamoDBJSON)
+}
+
+func (db *DB) writeSnap(w io.Writer, format string) error {
+	return writeFixture(w, db.snap(format))
+}
+
+func writeFixture(w io.Writer, jdb *JSONDB) error {
 	e := json.NewEncoder(w)
 	e.SetIndent("", "  ")
 	return e.Encode(jdb)
 }
 
+// snap returns the fixture of all tables, see WriteSnap and
+// WriteTypedSnap for format.
+func (db *DB) snap(format string) *JSONDB {
+	tables := db.tableList()
+	jdb := &JSONDB{
+		Tables: make([]*JSONTable, len(tables)),
+	}
+	for i, t := range tables {
+		jdb.Tables[i] = t.snap(format)
+	}
+	return jdb
+}
+
+// WriteFixture writes t as JSON fixture with a single table that can be
+// read with NewDBFromReader, in the format of WriteSnap.
+func (t *Table) WriteFixture(w io.Writer) error {
+	return writeFixture(w, &JSONDB{Tables: []*JSONTable{t.snap("")}})
+}
+
+func (t *Table) snap(format string) *JSONTable {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return t.snapLocked(format)
+}
+
+// snapLocked is snap for callers holding t.m. The items of the result
+// are in the order of t.items.
+func (t *Table) snapLocked(format string) *JSONTable {
+	jt := &JSONTable{Schema: t.schema, Name: t.name, Items: []map[string]interface{}{}}
+	if t.createTable != "" {
+		jt.Schema, jt.CreateTable = Schema{}, t.createTable
+	}
+	sets, ok := setTypes(t.items)
+	if format == formatDynamoDBJSON || t.format == formatDynamoDBJSON || !ok {
+		jt.Format = formatDynamoDBJSON
+		for _, item := range t.items {
+			jt.Items = append(jt.Items, encodeTypedItem(item))
+		}
+	} else {
+		jt.Sets = sets
+		_ = dynamodbattribute.UnmarshalListOfMaps(t.items, &jt.Items)
+	}
+	return jt
+}
+
+// Table returns the table named name or nil if db has no such table.
+func (db *DB) Table(name string) *Table {
+	db.m.RLock()
+	defer db.m.RUnlock()
+	return db.tables[name]
+}
+
+// table returns the table named name.
+func (db *DB) table(name *string) (*Table, error) {
+	db.m.RLock()
+	defer db.m.RUnlock()
+	if err := validateTableName(db, name); err != nil {
+		return nil, err
+	}
+	return db.tables[*name], nil
+}
+
+// tableList returns all tables in the order they were added.
+func (db *DB) tableList() []*Table {
+	db.m.RLock()
+	defer db.m.RUnlock()
+	tables := make([]*Table, len(db.tableNames))
+	for i, name := range db.tableNames {
+		tables[i] = db.tables[name]
+	}
+	return tables
+}
+
 func (db *DB) GetItem(in *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return db.GetItemWithContext(context.Background(), in)
+}
+
+func (db *DB) GetItemWithContext(ctx aws.Context, in *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	out, err := db.call(ctx, "GetItem", in, func() (interface{}, error) { return db.getItem(in) })
+	o, _ := out.(*dynamodb.GetItemOutput)
+	return o, err
+}
+
+func (db *DB) getItem(in *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 	if in == nil {
 		return nil, errs.Errorf("GetItem: %v: GetItemInput", ErrNil)
 	}
@@ -89,10 +440,10 @@ func (db *DB) GetItem(in *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error
 		msg := "GetItemInput fields: AttributesToGet, ExpressionAttributeNames, ProjectionExpression, ReturnConsumedCapacity"
 		return nil, errs.Errorf("GetItem: %v: %s", ErrUnimpl, msg)
 	}
-	if err := validateTableName(db, in.TableName); err != nil {
+	table, err := db.table(in.TableName)
+	if err != nil {
 		return nil, err
 	}
-	table := db.tables[*in.TableName]
 	item, err := table.Get(in.Key)
 	if err != nil {
 		return nil, err
@@ -100,74 +451,102 @@ func (db *DB) GetItem(in *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error
 	return &dynamodb.GetItemOutput{Item: item}, nil
 }
 
-func (db *DB) GetItemWithContext(_ aws.Context, in *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
-	return db.GetItem(in)
+func (db *DB) PutItem(in *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	return db.PutItemWithContext(context.Background(), in)
 }
 
-func (db *DB) PutItem(in *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+func (db *DB) PutItemWithContext(ctx aws.Context, in *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	out, err := db.call(ctx, "PutItem", in, func() (interface{}, error) { return db.putItem(in) })
+	o, _ := out.(*dynamodb.PutItemOutput)
+	return o, err
+}
+
+func (db *DB) putItem(in *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
 	if in == nil {
 		return nil, errs.Errorf("%v: PutItemInput", ErrNil)
 	}
-	if in.ConditionExpression != nil || in.ConditionalOperator != nil ||
-		in.Expected != nil || in.ExpressionAttributeNames != nil ||
-		in.ExpressionAttributeValues != nil {
-		msg := "ConditionExpression, ConditionalOperator, Expected, ExpressionAttributeNames, ExpressionAttributeValues, ReturnConsumedCapacity, ReturnItemCollectionMetrics, ReturnValues"
+	if in.ConditionalOperator != nil || in.Expected != nil {
+		msg := "ConditionalOperator, Expected, ReturnConsumedCapacity, ReturnItemCollectionMetrics"
 		return nil, errs.Errorf("PutItem: %v: %s", ErrUnimpl, msg)
 	}
-	if err := validateTableName(db, in.TableName); err != nil {
+	table, err := db.table(in.TableName)
+	if err != nil {
+		return nil, err
+	}
+	cond, err := parseCondition(in.ConditionExpression, in.ExpressionAttributeValues, in.ExpressionAttributeNames)
+	if err != nil {
 		return nil, err
 	}
-	table := db.tables[*in.TableName]
-	old, err := table.Put(in.Item)
+	old, err := table.put(in.Item, cond)
 	if err != nil {
 		return nil, err
 	}
+	if err := db.changed(table, in.Item); err != nil {
+		return nil, err
+	}
 	if in.ReturnValues != nil && *in.ReturnValues == "ALL_OLD" {
 		return &dynamodb.PutItemOutput{Attributes: old}, nil
 	}
 	return &dynamodb.PutItemOutput{Attributes: old}, nil
 }
 
-func (db *DB) PutItemWithContext(_ aws.Context, in *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
-	return db.PutItem(in)
+func (db *DB) DeleteItem(in *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	return db.DeleteItemWithContext(context.Background(), in)
 }
 
-func (db *DB) DeleteItem(in *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+func (db *DB) DeleteItemWithContext(ctx aws.Context, in *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	out, err := db.call(ctx, "DeleteItem", in, func() (interface{}, error) { return db.deleteItem(in) })
+	o, _ := out.(*dynamodb.DeleteItemOutput)
+	return o, err
+}
+
+func (db *DB) deleteItem(in *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
 	if in == nil {
 		return nil, errs.Errorf("%v: DeleteItemInput", ErrNil)
 	}
-	if in.ConditionExpression != nil || in.ConditionalOperator != nil ||
-		in.Expected != nil || in.ExpressionAttributeNames != nil ||
-		in.ExpressionAttributeValues != nil {
-		msg := "ConditionExpression, ConditionalOperator, Expected, ExpressionAttributeNames, ExpressionAttributeValues, ReturnConsumedCapacity, ReturnItemCollectionMetrics, ReturnValues"
+	if in.ConditionalOperator != nil || in.Expected != nil {
+		msg := "ConditionalOperator, Expected, ReturnConsumedCapacity, ReturnItemCollectionMetrics"
 		return nil, errs.Errorf("DeleteItem: %v: %s", ErrUnimpl, msg)
 	}
-	if err := validateTableName(db, in.TableName); err != nil {
+	table, err := db.table(in.TableName)
+	if err != nil {
+		return nil, err
+	}
+	cond, err := parseCondition(in.ConditionExpression, in.ExpressionAttributeValues, in.ExpressionAttributeNames)
+	if err != nil {
 		return nil, err
 	}
-	table := db.tables[*in.TableName]
-	old, err := table.Delete(in.Key)
+	old, err := table.delete(in.Key, cond)
 	if err != nil {
 		return nil, err
 	}
+	if err := db.changed(table, in.Key); err != nil {
+		return nil, err
+	}
 	if in.ReturnValues != nil && *in.ReturnValues == "ALL_OLD" {
 		return &dynamodb.DeleteItemOutput{Attributes: old}, nil
 	}
 	return &dynamodb.DeleteItemOutput{Attributes: old}, nil
 }
 
-func (db *DB) DeleteItemWithContext(_ aws.Context, in *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
-	return db.DeleteItem(in)
+func (db *DB) Query(in *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	return db.QueryWithContext(context.Background(), in)
 }
 
-func (db *DB) Query(in *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+func (db *DB) QueryWithContext(ctx aws.Context, in *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
+	out, err := db.call(ctx, "Query", in, func() (interface{}, error) { return db.query(in) })
+	o, _ := out.(*dynamodb.QueryOutput)
+	return o, err
+}
+
+func (db *DB) query(in *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
 	if err := validateQueryIntput(in); err != nil {
 		return nil, err
 	}
-	if err := validateTableName(db, in.TableName); err != nil {
+	table, err := db.table(in.TableName)
+	if err != nil {
 		return nil, err
 	}
-	table := db.tables[*in.TableName]
 	if err := validateIndexName(table, in.IndexName); err != nil {
 		return nil, err
 	}
@@ -179,17 +558,23 @@ func (db *DB) Query(in *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
 	if in.ScanIndexForward != nil && !*in.ScanIndexForward {
 		forward = false
 	}
+	filter, err := parseCondition(in.FilterExpression, in.ExpressionAttributeValues, in.ExpressionAttributeNames)
+	if err != nil {
+		return nil, err
+	}
 	items, err := table.Query(keyCond, in.IndexName, forward, in.ExclusiveStartKey)
 	if err != nil {
 		return nil, err
 	}
 	pagedItems := pageItems(items, in.Limit, db.pageSize)
+	filteredItems := filterItems(pagedItems, filter)
 	if in.Select != nil && *in.Select == "COUNT" {
-		count := int64(len(pagedItems))
-		return &dynamodb.QueryOutput{Count: &count, ScannedCount: &count}, nil
+		count := int64(len(filteredItems))
+		scannedCount := int64(len(pagedItems))
+		return &dynamodb.QueryOutput{Count: &count, ScannedCount: &scannedCount}, nil
 	}
 	out := &dynamodb.QueryOutput{
-		Items:            pagedItems,
+		Items:            filteredItems,
 		LastEvaluatedKey: table.getLastEvaluatedKey(items, pagedItems),
 	}
 	return out, nil
@@ -200,9 +585,9 @@ func validateQueryIntput(in *dynamodb.QueryInput) error {
 		return errs.Errorf("%v: QueryInput", ErrNil)
 	}
 	if in.AttributesToGet != nil || in.ConditionalOperator != nil ||
-		in.FilterExpression != nil || in.KeyConditions != nil ||
-		in.ProjectionExpression != nil || in.QueryFilter != nil {
-		msg := "AttributesToGet, ConditionalOperator, FilterExpression, KeyConditions, ProjectionExpression, QueryFilter"
+		in.KeyConditions != nil || in.ProjectionExpression != nil ||
+		in.QueryFilter != nil {
+		msg := "AttributesToGet, ConditionalOperator, KeyConditions, ProjectionExpression, QueryFilter"
 		return errs.Errorf("QueryItem: %v: %s", ErrUnimpl, msg)
 	}
 	if in.Select != nil && (*in.Select == "SPECIFIC_ATTRIBUTES" || *in.Select == "ALL_PROJECTED_ATTRIBUTES") {
@@ -217,11 +602,17 @@ func validateQueryIntput(in *dynamodb.QueryInput) error {
 	return nil
 }
 
-func (db *DB) QueryWithContext(_ aws.Context, in *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
-	return db.Query(in)
+func (db *DB) UpdateItem(in *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	return db.UpdateItemWithContext(context.Background(), in)
 }
 
-func (db *DB) UpdateItem(in *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+func (db *DB) UpdateItemWithContext(ctx aws.Context, in *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	out, err := db.call(ctx, "UpdateItem", in, func() (interface{}, error) { return db.updateItem(in) })
+	o, _ := out.(*dynamodb.UpdateItemOutput)
+	return o, err
+}
+
+func (db *DB) updateItem(in *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
 	if in == nil || in.UpdateExpression == nil || in.Key == nil || in.ExpressionAttributeValues == nil {
 		return nil, errs.Errorf("%v: UpdateItemInput [UpdateExpression | Key | ExpressionAttributeValues]", ErrNil)
 	}
@@ -232,21 +623,24 @@ func (db *DB) UpdateItem(in *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutp
 	if in.ReturnValues != nil && *in.ReturnValues != "NONE" && *in.ReturnValues != "ALL_OLD" && *in.ReturnValues != "ALL_NEW" {
 		return nil, errs.Errorf("UpdateItemInput.ReturnValues: %v: expected NONE, ALL_OLD or ALL_NEW", ErrUnimpl)
 	}
-	if err := validateTableName(db, in.TableName); err != nil {
+	table, err := db.table(in.TableName)
+	if err != nil {
 		return nil, err
 	}
-	table := db.tables[*in.TableName]
 	updateExpr, err := parseUpdateExpr(in.UpdateExpression, in.ExpressionAttributeValues, in.ExpressionAttributeNames)
 	if err != nil {
 		return nil, err
 	}
-	item, err := table.Update(in.Key, updateExpr, in.ReturnValues)
+	cond, err := parseCondition(in.ConditionExpression, in.ExpressionAttributeValues, in.ExpressionAttributeNames)
+	if err != nil {
+		return nil, err
+	}
+	item, err := table.Update(in.Key, updateExpr, cond, in.ReturnValues)
 	if err != nil {
 		return nil, err
 	}
+	if err := db.changed(table, in.Key); err != nil {
+		return nil, err
+	}
 	return &dynamodb.UpdateItemOutput{Attributes: item}, nil
 }
-
-func (db *DB) UpdateItemWithContext(_ aws.Context, in *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
-	return db.UpdateItem(in)
-}
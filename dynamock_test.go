@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -48,6 +49,10 @@ func strPtr(s string) *string {
 	return &s
 }
 
+func boolPtr(b bool) *bool {
+	return &b
+}
+
 func CloseIgnoreErr(c io.Closer) {
 	_ = c.Close()
 }
@@ -155,6 +160,226 @@ func TestWriteDBSnap(t *testing.T) {
 	require.JSONEq(t, want, sb.String())
 }
 
+func TestDBFromReaderBinary(t *testing.T) {
+	db := ReadTestdataDB(t, "binary.json")
+	et := db.tables["event"]
+	require.Equal(t, "binary", et.schema.PrimaryKey.SortKey.Type)
+	require.Equal(t, []byte{0xff, 0x00}, et.items[0]["ts"].B)
+	require.Equal(t, et.items[2], et.byPrimary["d1"]["\x00\x01"])
+	require.Equal(t, "stop", *et.byIndex["tsGSI"]["\xff\x00"][0]["kind"].S)
+
+	sb := &bytes.Buffer{}
+	err := db.WriteSnap(sb)
+	require.NoError(t, err)
+	want := string(ReadTestdataBytes(t, "binary.json"))
+	require.JSONEq(t, want, sb.String())
+}
+
+func TestDBFromReaderBase64Err(t *testing.T) {
+	r := strings.NewReader(`{"tables" : [
+		{
+			"name": "event",
+			"schema": {
+				"primaryKey": {
+					"partitionKey": { "name": "ts", "type": "binary" }
+				}
+			},
+			"items": [ { "ts": "not base64!" } ]
+		}
+	 ] }`)
+	_, err := NewDBFromReader(r)
+	requireErrIs(t, err, ErrItemValidation)
+	requireErrIs(t, err, ErrInvalidType)
+}
+
+func TestDBFromReaderSets(t *testing.T) {
+	db := ReadTestdataDB(t, "sets.json")
+	pt := db.tables["post"]
+	require.Equal(t, strSet("go", "aws"), pt.items[0]["tags"])
+	require.Equal(t, numSet("3", "1"), pt.items[0]["scores"])
+	require.Equal(t, [][]byte{{0, 1}}, pt.items[0]["hashes"].BS)
+	require.Len(t, pt.items[2]["links"].L, 2)
+
+	sb := &bytes.Buffer{}
+	err := db.WriteSnap(sb)
+	require.NoError(t, err)
+	want := string(ReadTestdataBytes(t, "sets.json"))
+	require.JSONEq(t, want, sb.String())
+}
+
+func TestDBFromReaderSetsErr(t *testing.T) {
+	testCases := map[string]struct {
+		sets  string
+		items string
+		want  error
+	}{
+		"bad_type":     {sets: `{"tags": "list"}`, items: `{"id": "1"}`, want: ErrUnknownType},
+		"not_list":     {sets: `{"tags": "string"}`, items: `{"id": "1", "tags": "a"}`, want: ErrInvalidType},
+		"member_type":  {sets: `{"tags": "string"}`, items: `{"id": "1", "tags": [1]}`, want: ErrInvalidType},
+		"bad_base64":   {sets: `{"tags": "binary"}`, items: `{"id": "1", "tags": ["not base64!"]}`, want: ErrInvalidType},
+		"empty_set":    {sets: `{"tags": "number"}`, items: `{"id": "1", "tags": []}`, want: ErrEmptySet},
+		"duplicate":    {sets: `{"tags": "number"}`, items: `{"id": "1", "tags": [1, 1.0]}`, want: ErrDuplicate},
+		"binary_empty": {sets: `{"tags": "binary"}`, items: `{"id": "1", "tags": []}`, want: ErrEmptySet},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			r := strings.NewReader(`{"tables" : [ {
+				"name": "post",
+				"schema": { "primaryKey": { "partitionKey": { "name": "id", "type": "string" } } },
+				"sets": ` + tc.sets + `,
+				"items": [ ` + tc.items + ` ]
+			} ] }`)
+			_, err := NewDBFromReader(r)
+			requireErrIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestWriteSnapSetConflicts(t *testing.T) {
+	testCases := map[string][]Item{
+		"set_and_list": {
+			{"id": {S: strPtr("1")}, "tags": strSet("a")},
+			{"id": {S: strPtr("2")}, "tags": {L: []*dynamodb.AttributeValue{{N: strPtr("1")}}}},
+		},
+		"set_types": {
+			{"id": {S: strPtr("1")}, "tags": strSet("a")},
+			{"id": {S: strPtr("2")}, "tags": numSet("1")},
+		},
+		"nested_in_map":  {{"id": {S: strPtr("1")}, "m": {M: Item{"tags": strSet("a")}}}},
+		"nested_in_list": {{"id": {S: strPtr("1")}, "l": {L: []*dynamodb.AttributeValue{{M: Item{"tags": numSet("1")}}}}}},
+	}
+	for name, items := range testCases {
+		items := items
+		t.Run(name, func(t *testing.T) {
+			db := NewDB()
+			table := &Table{name: "post", schema: productSchema(), items: items}
+			require.NoError(t, table.index())
+			require.NoError(t, db.addTable(table))
+			sb := &bytes.Buffer{}
+			require.NoError(t, db.WriteSnap(sb))
+			require.Contains(t, sb.String(), `"format": "dynamodb"`)
+			db2, err := NewDBFromReader(sb)
+			require.NoError(t, err)
+			require.Equal(t, items, db2.tables["post"].items)
+		})
+	}
+
+	_, ok := setTypes([]Item{{"l": {L: []*dynamodb.AttributeValue{{S: strPtr("a")}}}, "m": {M: Item{"n": {N: strPtr("1")}}}}})
+	require.True(t, ok)
+}
+
+func TestDBFromReaderTyped(t *testing.T) {
+	db := ReadTestdataDB(t, "typed.json")
+	at := db.tables["asset"]
+	item := at.byPrimary["ann"]["\x00\x01"]
+	require.Equal(t, "12345678901234567890.123456789", *item["value"].N)
+	require.Equal(t, strSet("car", "blue"), item["tags"])
+	require.Equal(t, numSet("0.5", "1.25"), item["ratios"])
+	require.Equal(t, [][]byte{{1, 2}, {3, 4}}, item["hashes"].BS)
+	require.True(t, *item["insured"].BOOL)
+	require.True(t, *item["note"].NULL)
+	require.Equal(t, "2020", *item["history"].L[1].N)
+	require.Equal(t, "4", *item["specs"].M["doors"].N)
+	require.Equal(t, "1", *at.byPrimary["bob"]["\x00\x01"]["label"].S)
+	require.Len(t, at.byIndex["valueGSI"]["10"], 1)
+
+	sb := &bytes.Buffer{}
+	err := db.WriteSnap(sb)
+	require.NoError(t, err)
+	want := string(ReadTestdataBytes(t, "typed.json"))
+	require.JSONEq(t, want, sb.String())
+}
+
+func TestDBFromReaderTypedDetect(t *testing.T) {
+	r := strings.NewReader(`{"tables" : [ {
+		"name": "product",
+		"schema": { "primaryKey": { "partitionKey": { "name": "id", "type": "string" } } },
+		"items": [ { "id": { "S": "1" }, "price": { "N": "1.50" } } ]
+	} ] }`)
+	db, err := NewDBFromReader(r)
+	require.NoError(t, err)
+	require.Equal(t, "1.50", *db.tables["product"].items[0]["price"].N)
+
+	r = strings.NewReader(`{"tables" : [ {
+		"name": "product",
+		"schema": { "primaryKey": { "partitionKey": { "name": "id", "type": "string" } } },
+		"format": "json",
+		"items": [ { "id": "1", "price": { "N": "1.50" } } ]
+	} ] }`)
+	db, err = NewDBFromReader(r)
+	require.NoError(t, err)
+	require.Equal(t, "1.50", *db.tables["product"].items[0]["price"].M["N"].S)
+}
+
+func TestWriteTypedSnap(t *testing.T) {
+	db := ReadTestdataDB(t, "sets.json")
+	sb := &bytes.Buffer{}
+	err := db.WriteTypedSnap(sb)
+	require.NoError(t, err)
+
+	db2, err := NewDBFromReader(sb)
+	require.NoError(t, err)
+	pt, pt2 := db.tables["post"], db2.tables["post"]
+	require.Equal(t, formatDynamoDBJSON, pt2.format)
+	require.Equal(t, len(pt.items), len(pt2.items))
+	for i := range pt.items {
+		require.True(t, mapEqual(pt.items[i], pt2.items[i]))
+	}
+}
+
+func TestTableWriteFixture(t *testing.T) {
+	db := ReadTestdataDB(t, "sets.json")
+	require.Nil(t, db.Table("MISSING"))
+	sb := &bytes.Buffer{}
+	require.NoError(t, db.Table("post").WriteFixture(sb))
+
+	db2, err := NewDBFromReader(sb)
+	require.NoError(t, err)
+	require.Equal(t, []string{"post"}, db2.tableNames)
+	require.True(t, Diff(db, db2).Empty())
+}
+
+func TestDBFromReaderTypedErr(t *testing.T) {
+	testCases := map[string]struct {
+		format string
+		item   string
+		want   error
+	}{
+		"bad_format":     {format: "xml", item: `{"id": {"S": "1"}}`, want: ErrUnknownType},
+		"not_object":     {format: "dynamodb", item: `{"id": "1"}`, want: ErrInvalidAttr},
+		"two_types":      {format: "dynamodb", item: `{"id": {"S": "1", "N": "1"}}`, want: ErrInvalidAttr},
+		"unknown_type":   {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"X": "1"}}`, want: ErrInvalidAttr},
+		"number_N":       {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"N": 1}}`, want: ErrInvalidType},
+		"bad_base64":     {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"B": "!"}}`, want: ErrInvalidType},
+		"bad_B":          {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"B": 1}}`, want: ErrInvalidType},
+		"bad_BOOL":       {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"BOOL": "true"}}`, want: ErrInvalidType},
+		"bad_M":          {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"M": []}}`, want: ErrInvalidType},
+		"bad_M_member":   {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"M": {"a": 1}}}`, want: ErrInvalidAttr},
+		"bad_L":          {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"L": {}}}`, want: ErrInvalidType},
+		"bad_L_member":   {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"L": [1]}}`, want: ErrInvalidAttr},
+		"bad_SS_member":  {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"SS": [1]}}`, want: ErrInvalidType},
+		"bad_BS_member":  {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"BS": ["!"]}}`, want: ErrInvalidType},
+		"empty_NS":       {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"NS": []}}`, want: ErrEmptySet},
+		"invalid_number": {format: "dynamodb", item: `{"id": {"S": "1"}, "x": {"NS": ["x"]}}`, want: ErrInvalidNumber},
+		"missing_key":    {format: "", item: `{"x": {"S": "1"}}`, want: ErrMissingAttribute},
+		"detect_json":    {format: "", item: `{"id": {"Id": "1"}}`, want: ErrPrimaryKeyVal},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			r := strings.NewReader(`{"tables" : [ {
+				"name": "product",
+				"schema": { "primaryKey": { "partitionKey": { "name": "id", "type": "string" } } },
+				"format": "` + tc.format + `",
+				"items": [ ` + tc.item + ` ]
+			} ] }`)
+			_, err := NewDBFromReader(r)
+			requireErrIs(t, err, tc.want)
+		})
+	}
+}
+
 func TestGetItem(t *testing.T) {
 	db := ReadTestdataDB(t, "db.json")
 
@@ -216,6 +441,19 @@ func TestGetNoItem(t *testing.T) {
 	require.Nil(t, out.Item)
 }
 
+func TestGetItemNumberKey(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	for _, id := range []string{"1", "1.0", "001.000", "1E0", "0.1e1"} {
+		in := &dynamodb.GetItemInput{
+			TableName: strPtr("person"),
+			Key:       Item{"id": {N: strPtr(id)}},
+		}
+		out, err := db.GetItem(in)
+		require.NoError(t, err)
+		require.JSONEq(t, `{ "id": 1, "name": "Jon", "phone": "111", "age": 11 }`, ItemToJSON(out.Item))
+	}
+}
+
 func TestGetItemNilErr(t *testing.T) {
 	db := ReadTestdataDB(t, "db.json")
 	_, err := db.GetItem(nil)
@@ -286,6 +524,12 @@ func TestGetKeyStringErr(t *testing.T) {
 	require.Error(t, err)
 	requireErrIs(t, err, ErrInvalidType)
 
+	_, err = getKeyString(&dynamodb.AttributeValue{N: strPtr("1x")}, "number")
+	requireErrIs(t, err, ErrInvalidNumber)
+
+	_, err = getKeyString(attr, "binary")
+	requireErrIs(t, err, ErrInvalidType)
+
 	_, err = getKeyString(attr, "badType")
 	require.Error(t, err)
 	requireErrIs(t, err, ErrInvalidType)
@@ -442,7 +686,7 @@ func TestPutErr(t *testing.T) {
 	requireErrIs(t, err, ErrNil)
 
 	in := &dynamodb.PutItemInput{
-		ConditionExpression: strPtr("??"),
+		ConditionalOperator: strPtr("AND"),
 	}
 	_, err = db.PutItem(in)
 	requireErrIs(t, err, ErrUnimpl)
@@ -457,6 +701,74 @@ func TestPutErr(t *testing.T) {
 	requireErrIs(t, err, ErrUnknownTable)
 }
 
+func TestPutItemNumbers(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	in := &dynamodb.PutItemInput{
+		TableName:    strPtr("person"),
+		Item:         Item{"id": {N: strPtr("1.00")}, "name": {S: strPtr("Jon")}},
+		ReturnValues: strPtr("ALL_OLD"),
+	}
+	length := len(db.tables["person"].items)
+	out, err := db.PutItem(in)
+	require.NoError(t, err)
+	require.JSONEq(t, `{ "id": 1, "name": "Jon", "phone": "111", "age": 11 }`, ItemToJSON(out.Attributes))
+	require.Equal(t, length, len(db.tables["person"].items))
+
+	// 38 significant digits, ordered exactly
+	for id, age := range []string{"12345678901234567890123456789012345679", "12345678901234567890123456789012345678"} {
+		in := &dynamodb.PutItemInput{
+			TableName: strPtr("person"),
+			Item:      Item{"id": {N: strPtr(strconv.Itoa(100 + id))}, "name": {S: strPtr("Old")}, "age": {N: strPtr(age)}},
+		}
+		_, err = db.PutItem(in)
+		require.NoError(t, err)
+	}
+	qin := &dynamodb.QueryInput{
+		TableName:                 strPtr("person"),
+		IndexName:                 strPtr("nameGSI"),
+		KeyConditionExpression:    strPtr("name = :name AND age > :age"),
+		ExpressionAttributeValues: Item{":name": {S: strPtr("Old")}, ":age": {N: strPtr("12345678901234567890123456789012345678")}},
+	}
+	qout, err := db.Query(qin)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(qout.Items))
+	require.Equal(t, "100", *qout.Items[0]["id"].N)
+
+	qin.SetKeyConditionExpression("name = :name")
+	qout, err = db.Query(qin)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(qout.Items))
+	require.Equal(t, "101", *qout.Items[0]["id"].N)
+	require.Equal(t, "100", *qout.Items[1]["id"].N)
+}
+
+func TestPutItemNumberErr(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	in := &dynamodb.PutItemInput{
+		TableName: strPtr("person"),
+		Item:      Item{"id": {N: strPtr("1E126")}},
+	}
+	_, err := db.PutItem(in)
+	requireErrIs(t, err, ErrInvalidNumber)
+
+	in.Item = Item{"id": {N: strPtr("1")}, "score": {N: strPtr("123456789012345678901234567890123456789")}}
+	_, err = db.PutItem(in)
+	requireErrIs(t, err, ErrItemValidation)
+	requireErrIs(t, err, ErrInvalidNumber)
+
+	in.Item = Item{"id": {N: strPtr("1")}, "scores": {NS: []*string{strPtr("1"), strPtr("x")}}}
+	_, err = db.PutItem(in)
+	requireErrIs(t, err, ErrInvalidNumber)
+
+	in.Item = Item{"id": {N: strPtr("1")}, "scores": {L: []*dynamodb.AttributeValue{{N: strPtr("1e-200")}}}}
+	_, err = db.PutItem(in)
+	requireErrIs(t, err, ErrInvalidNumber)
+
+	in.Item = Item{"id": {N: strPtr("1")}, "scores": {M: Item{"x": {N: strPtr("--1")}}}}
+	_, err = db.PutItem(in)
+	requireErrIs(t, err, ErrInvalidNumber)
+}
+
 func TestPutInvalidItemErr(t *testing.T) {
 	db := ReadTestdataDB(t, "db.json")
 	in := &dynamodb.PutItemInput{
@@ -516,7 +828,7 @@ func TestDeleteItemErr(t *testing.T) {
 	requireErrIs(t, err, ErrNil)
 
 	in := &dynamodb.DeleteItemInput{
-		ConditionExpression: strPtr("??"),
+		ConditionalOperator: strPtr("AND"),
 	}
 	_, err = db.DeleteItem(in)
 	requireErrIs(t, err, ErrUnimpl)
@@ -787,6 +1099,67 @@ func TestQueryCompositePK(t *testing.T) {
 	require.Nil(t, out.LastEvaluatedKey)
 }
 
+func TestQueryBinarySortCond(t *testing.T) {
+	db := ReadTestdataDB(t, "binary.json")
+	in := &dynamodb.QueryInput{
+		TableName:                 strPtr("event"),
+		KeyConditionExpression:    strPtr("device = :device"),
+		ExpressionAttributeValues: Item{":device": {S: strPtr("d1")}},
+	}
+	out, err := db.Query(in)
+	require.NoError(t, err)
+	cols := []string{"device", "kind"}
+	want := `
+device,  kind
+    d1, start
+    d1,  ping
+    d1, pause
+    d1,  stop
+`[1:]
+	require.Equal(t, want, SnapString(out.Items, cols))
+
+	in.SetKeyConditionExpression("device = :device AND ts BETWEEN :from AND :to")
+	in.SetExpressionAttributeValues(Item{
+		":device": {S: strPtr("d1")},
+		":from":   {B: []byte{0x00, 0x02}},
+		":to":     {B: []byte{0x01, 0x00}},
+	})
+	out, err = db.Query(in)
+	require.NoError(t, err)
+	want = `
+device,  kind
+    d1,  ping
+    d1, pause
+`[1:]
+	require.Equal(t, want, SnapString(out.Items, cols))
+
+	in.SetKeyConditionExpression("device = :device AND begins_with(ts, :prefix)")
+	in.SetExpressionAttributeValues(Item{
+		":device": {S: strPtr("d1")},
+		":prefix": {B: []byte{0x00}},
+	})
+	out, err = db.Query(in)
+	require.NoError(t, err)
+	want = `
+device,  kind
+    d1, start
+    d1,  ping
+`[1:]
+	require.Equal(t, want, SnapString(out.Items, cols))
+
+	in.SetIndexName("tsGSI")
+	in.SetKeyConditionExpression("ts = :ts")
+	in.SetExpressionAttributeValues(Item{":ts": {B: []byte{0x00, 0x01}}})
+	out, err = db.Query(in)
+	require.NoError(t, err)
+	want = `
+device,  kind
+    d1, start
+    d2, start
+`[1:]
+	require.Equal(t, want, SnapString(out.Items, cols))
+}
+
 func TestUpdateItem(t *testing.T) {
 	db := ReadTestdataDB(t, "db.json")
 	in := &dynamodb.UpdateItemInput{
@@ -829,6 +1202,91 @@ func TestUpdateItem(t *testing.T) {
 	require.JSONEq(t, want, ItemToJSON(out.Attributes))
 }
 
+func TestUpdateItemCreate(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	in := updateInputFixture().SetKey(Item{"id": {S: strPtr("100")}})
+	out, err := db.UpdateItem(in)
+	require.NoError(t, err)
+	want := `{ "id": "100", "price": 100 }`
+	require.JSONEq(t, want, ItemToJSON(out.Attributes))
+
+	qin := &dynamodb.QueryInput{
+		TableName:                 strPtr("product"),
+		KeyConditionExpression:    strPtr("id = :id"),
+		ExpressionAttributeValues: Item{":id": {S: strPtr("100")}},
+	}
+	qout, err := db.Query(qin)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(qout.Items))
+	require.JSONEq(t, want, ItemToJSON(qout.Items[0]))
+}
+
+func TestUpdateItemIndexUpdate(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	in := &dynamodb.UpdateItemInput{
+		TableName:                 strPtr("person"),
+		Key:                       Item{"id": {N: strPtr("4")}},
+		UpdateExpression:          strPtr("SET age=:age REMOVE phone"),
+		ExpressionAttributeValues: Item{":age": {N: strPtr("10")}},
+	}
+	out, err := db.UpdateItem(in)
+	require.NoError(t, err)
+	require.Nil(t, out.Attributes)
+	qin := &dynamodb.QueryInput{
+		TableName:                 strPtr("person"),
+		IndexName:                 strPtr("nameGSI"),
+		KeyConditionExpression:    strPtr("name = :name"),
+		ExpressionAttributeValues: Item{":name": {S: strPtr("Jen")}},
+	}
+	qout, err := db.Query(qin)
+	require.NoError(t, err)
+	cols := []string{"id", "name", "age"}
+	want := `
+id, name, age
+ 4,  Jen,  10
+ 8,  Jen,  15
+`[1:]
+	require.Equal(t, want, SnapString(qout.Items, cols))
+	require.Empty(t, db.tables["person"].byIndex["phoneGSI"]["444"])
+	require.Equal(t, 9, len(db.tables["person"].items))
+}
+
+func TestUpdateItemValidationErr(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	in := updateInputFixture().SetUpdateExpression("SET id=:price")
+	_, err := db.UpdateItem(in)
+	requireErrIs(t, err, ErrInvalidUpdateExpression)
+
+	in = updateInputFixture().SetUpdateExpression("REMOVE id")
+	_, err = db.UpdateItem(in)
+	requireErrIs(t, err, ErrInvalidUpdateExpression)
+
+	in = updateInputFixture().SetExpressionAttributeValues(Item{":price": {NS: []*string{}}})
+	_, err = db.UpdateItem(in)
+	requireErrIs(t, err, ErrItemValidation)
+	requireErrIs(t, err, ErrEmptySet)
+	want := `{ "id": "1", "name": "red pen", "price": 11 }`
+	require.JSONEq(t, want, ItemToJSON(db.tables["product"].items[0]))
+}
+
+func TestDeleteItemQuery(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	in := &dynamodb.DeleteItemInput{
+		TableName: strPtr("product"),
+		Key:       Item{"id": {S: strPtr("1")}},
+	}
+	_, err := db.DeleteItem(in)
+	require.NoError(t, err)
+	qin := &dynamodb.QueryInput{
+		TableName:                 strPtr("product"),
+		KeyConditionExpression:    strPtr("id = :id"),
+		ExpressionAttributeValues: Item{":id": {S: strPtr("1")}},
+	}
+	out, err := db.Query(qin)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(out.Items))
+}
+
 func updateInputFixture() *dynamodb.UpdateItemInput {
 	return &dynamodb.UpdateItemInput{
 		TableName:                 strPtr("product"),
@@ -865,3 +1323,151 @@ func TestUpdateItemErr(t *testing.T) {
 	_, err = db.UpdateItem(in)
 	requireErrIs(t, err, ErrInvalidUpdateExpression)
 }
+
+func TestPutItemCondition(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	in := &dynamodb.PutItemInput{
+		TableName:           strPtr("product"),
+		Item:                Item{"id": {S: strPtr("1")}, "name": {S: strPtr("new pen")}},
+		ConditionExpression: strPtr("attribute_not_exists(id)"),
+	}
+	_, err := db.PutItem(in)
+	requireErrIs(t, err, ErrConditionalCheckFailed)
+
+	in.Item["id"] = &dynamodb.AttributeValue{S: strPtr("5")}
+	_, err = db.PutItem(in)
+	require.NoError(t, err)
+	require.Equal(t, "new pen", *db.tables["product"].byPrimary["5"][""]["name"].S)
+
+	in = &dynamodb.PutItemInput{
+		TableName:                 strPtr("product"),
+		Item:                      Item{"id": {S: strPtr("1")}, "price": {N: strPtr("12")}},
+		ConditionExpression:       strPtr("#p < :max"),
+		ExpressionAttributeNames:  map[string]*string{"#p": strPtr("price")},
+		ExpressionAttributeValues: Item{":max": {N: strPtr("12")}},
+	}
+	_, err = db.PutItem(in)
+	require.NoError(t, err)
+	_, err = db.PutItem(in)
+	requireErrIs(t, err, ErrConditionalCheckFailed)
+
+	in.ConditionExpression = strPtr("#p < ")
+	_, err = db.PutItem(in)
+	requireErrIs(t, err, ErrInvalidCondition)
+}
+
+func TestDeleteItemCondition(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	in := &dynamodb.DeleteItemInput{
+		TableName:                 strPtr("product"),
+		Key:                       Item{"id": {S: strPtr("1")}},
+		ConditionExpression:       strPtr("price > :price"),
+		ExpressionAttributeValues: Item{":price": {N: strPtr("11")}},
+	}
+	_, err := db.DeleteItem(in)
+	requireErrIs(t, err, ErrConditionalCheckFailed)
+	require.Len(t, db.tables["product"].items, 4)
+
+	in.ExpressionAttributeValues[":price"] = &dynamodb.AttributeValue{N: strPtr("10")}
+	_, err = db.DeleteItem(in)
+	require.NoError(t, err)
+	require.Len(t, db.tables["product"].items, 3)
+
+	in.ConditionExpression = strPtr("price > :missing")
+	_, err = db.DeleteItem(in)
+	requireErrIs(t, err, ErrSubstitution)
+}
+
+func TestUpdateItemCondition(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	in := updateInputFixture()
+	in.ConditionExpression = strPtr("price = :price")
+	_, err := db.UpdateItem(in)
+	requireErrIs(t, err, ErrConditionalCheckFailed)
+
+	in.ConditionExpression = strPtr("price <> :price")
+	out, err := db.UpdateItem(in)
+	require.NoError(t, err)
+	require.Equal(t, "100", *out.Attributes["price"].N)
+
+	in.ConditionExpression = strPtr("price <>")
+	_, err = db.UpdateItem(in)
+	requireErrIs(t, err, ErrInvalidCondition)
+}
+
+func TestUpdateItemAddDelete(t *testing.T) {
+	db := ReadTestdataDB(t, "sets.json")
+	in := &dynamodb.UpdateItemInput{
+		TableName:        strPtr("post"),
+		Key:              Item{"id": {S: strPtr("1")}},
+		UpdateExpression: strPtr("ADD tags :tags, views :one DELETE scores :scores"),
+		ExpressionAttributeValues: Item{
+			":tags":   strSet("aws", "db"),
+			":one":    {N: strPtr("1")},
+			":scores": numSet("1.0"),
+		},
+		ReturnValues: strPtr("ALL_NEW"),
+	}
+	out, err := db.UpdateItem(in)
+	require.NoError(t, err)
+	require.True(t, attrEqual(strSet("go", "aws", "db"), out.Attributes["tags"]))
+	require.Equal(t, numSet("3"), out.Attributes["scores"])
+	require.Equal(t, "1", *out.Attributes["views"].N)
+
+	out, err = db.UpdateItem(in)
+	require.NoError(t, err)
+	require.Equal(t, "2", *out.Attributes["views"].N)
+
+	in.UpdateExpression = strPtr("DELETE scores :scores")
+	in.ExpressionAttributeValues = Item{":scores": numSet("3")}
+	out, err = db.UpdateItem(in)
+	require.NoError(t, err)
+	require.NotContains(t, out.Attributes, "scores")
+
+	in.UpdateExpression = strPtr("ADD tags :one")
+	in.ExpressionAttributeValues = Item{":one": {N: strPtr("1")}}
+	_, err = db.UpdateItem(in)
+	requireErrIs(t, err, ErrInvalidType)
+
+	in.UpdateExpression = strPtr("DELETE tags :one")
+	_, err = db.UpdateItem(in)
+	requireErrIs(t, err, ErrInvalidType)
+
+	in.UpdateExpression = strPtr("ADD id :one")
+	_, err = db.UpdateItem(in)
+	requireErrIs(t, err, ErrInvalidUpdateExpression)
+}
+
+func TestQueryFilter(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	in := &dynamodb.QueryInput{
+		TableName:                 strPtr("person"),
+		IndexName:                 strPtr("nameGSI"),
+		KeyConditionExpression:    strPtr("#n = :name"),
+		FilterExpression:          strPtr("phone IN (:p1, :p2) AND attribute_exists(age)"),
+		ExpressionAttributeNames:  map[string]*string{"#n": strPtr("name")},
+		ExpressionAttributeValues: Item{":name": {S: strPtr("Jen")}, ":p1": {S: strPtr("444")}, ":p2": {S: strPtr("999")}},
+	}
+	out, err := db.Query(in)
+	require.NoError(t, err)
+	require.Len(t, out.Items, 1)
+	require.Equal(t, "4", *out.Items[0]["id"].N)
+
+	in.Select = strPtr("COUNT")
+	out, err = db.Query(in)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), *out.Count)
+	require.Equal(t, int64(2), *out.ScannedCount)
+
+	in.Select = nil
+	in.SetLimit(int64(1))
+	in.FilterExpression = strPtr("phone = :p1")
+	out, err = db.Query(in)
+	require.NoError(t, err)
+	require.Empty(t, out.Items)
+	require.NotNil(t, out.LastEvaluatedKey)
+
+	in.FilterExpression = strPtr("phone = :missing")
+	_, err = db.Query(in)
+	requireErrIs(t, err, ErrSubstitution)
+}
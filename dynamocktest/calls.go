@@ -0,0 +1,51 @@
+package dynamocktest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/juliaogris/dynamock"
+)
+
+// AssertCalls checks that r recorded exactly the calls want in order,
+// formatted as by dynamock.Call.String, e.g.
+//
+//	AssertCalls(t, r, "GetItem product", "PutItem product")
+func AssertCalls(t testing.TB, r *dynamock.Recorder, want ...string) {
+	t.Helper()
+	got := r.Strings()
+	if equal(got, want) {
+		return
+	}
+	t.Errorf("dynamocktest: unexpected calls:\nwant:\n%s\ngot:\n%s", formatCalls(want), formatCalls(got))
+}
+
+// AssertCount checks that r recorded n calls of the operation op on
+// table, e.g. AssertCount(t, r, "Scan", "", 0) checks that nothing was
+// scanned. Empty op or table match all operations or tables.
+func AssertCount(t testing.TB, r *dynamock.Recorder, op, table string, n int) {
+	t.Helper()
+	if got := r.Count(op, table); got != n {
+		call := dynamock.Call{Op: op, Table: table}
+		t.Errorf("dynamocktest: want %d calls of '%s', got %d:\n%s", n, call, got, formatCalls(r.Strings()))
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatCalls(calls []string) string {
+	if len(calls) == 0 {
+		return "  (none)"
+	}
+	return "  " + strings.Join(calls, "\n  ")
+}
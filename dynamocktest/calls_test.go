@@ -0,0 +1,44 @@
+package dynamocktest
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/juliaogris/dynamock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertCalls(t *testing.T) {
+	db := readDB(t)
+	rec := db.Record()
+	AssertCalls(t, rec)
+	_, err := db.GetItem(&dynamodb.GetItemInput{TableName: strPtr("session"), Key: dynamock.Item{"id": {S: strPtr("a")}}})
+	require.NoError(t, err)
+	_, _ = db.Scan(&dynamodb.ScanInput{TableName: strPtr("event")})
+	AssertCalls(t, rec, "GetItem session", "Scan event")
+	AssertCount(t, rec, "GetItem", "", 1)
+	AssertCount(t, rec, "", "", 2)
+
+	r := &recorder{}
+	AssertCalls(r, rec, "GetItem session")
+	AssertCalls(r, rec, "GetItem session", "Scan session")
+	require.Len(t, r.errs, 2)
+	want := `
+dynamocktest: unexpected calls:
+want:
+  GetItem session
+got:
+  GetItem session
+  Scan event`[1:]
+	require.Equal(t, want, r.errs[0])
+
+	r = &recorder{}
+	AssertCount(r, rec, "Scan", "", 0)
+	rec.Reset()
+	AssertCount(r, rec, "PutItem", "session", 1)
+	require.Len(t, r.errs, 2)
+	want = `
+dynamocktest: want 1 calls of 'PutItem session', got 0:
+  (none)`[1:]
+	require.Equal(t, want, r.errs[1])
+}
@@ -0,0 +1,177 @@
+// Package dynamocktest provides test helpers for dynamock DBs, such as
+// assertions against golden files.
+package dynamocktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/juliaogris/dynamock"
+)
+
+// update is prefixed, so that it does not clash with an -update flag of
+// the test binary, which is honored as well, see updating.
+var update = flag.Bool("dynamocktest.update", false, "rewrite golden files of dynamocktest assertions")
+
+// updating returns whether golden files are rewritten, either with
+// -dynamocktest.update or with an -update flag defined by the test binary.
+func updating() bool {
+	if *update {
+		return true
+	}
+	f := flag.Lookup("update")
+	return f != nil && f.Value.String() == "true"
+}
+
+// Ignored replaces the values of ignored attributes in golden files.
+const Ignored = "<ignored>"
+
+// Option configures golden file assertions.
+type Option func(*options)
+
+type options struct {
+	ignore [][]string
+}
+
+// Ignore replaces the values of the attributes at the dot separated
+// paths, e.g. "createdAt" or "meta.requestID", with Ignored in the items
+// of every table before comparing, so that volatile values such as
+// timestamps and UUIDs do not fail assertions.
+func Ignore(paths ...string) Option {
+	return func(o *options) {
+		for _, path := range paths {
+			o.ignore = append(o.ignore, strings.Split(path, "."))
+		}
+	}
+}
+
+// AssertDB compares db, as written by DB.WriteSnap, with the golden file.
+// On mismatch it reports the added, removed and modified items, see
+// dynamock.Diff. If the test binary is run with -dynamocktest.update, or
+// with an -update flag it defines, the golden file is rewritten instead.
+func AssertDB(t testing.TB, db *dynamock.DB, golden string, opts ...Option) {
+	t.Helper()
+	assertGolden(t, db.WriteSnap, golden, opts)
+}
+
+// AssertTable compares table, as written by Table.WriteFixture, with the
+// golden file, see AssertDB.
+func AssertTable(t testing.TB, table *dynamock.Table, golden string, opts ...Option) {
+	t.Helper()
+	assertGolden(t, table.WriteFixture, golden, opts)
+}
+
+func assertGolden(t testing.TB, write func(io.Writer) error, golden string, opts []Option) {
+	t.Helper()
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	got, err := snap(write, o.ignore)
+	if err != nil {
+		t.Errorf("dynamocktest: cannot snapshot: %v", err)
+		return
+	}
+	if updating() {
+		if err := writeGolden(golden, got); err != nil {
+			t.Errorf("dynamocktest: cannot update golden file: %v", err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Errorf("dynamocktest: %v (run with -dynamocktest.update to create it)", err)
+		return
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+	wantDB, err := dynamock.NewDBFromReader(bytes.NewReader(want))
+	if err != nil {
+		t.Errorf("dynamocktest: golden file %s: %v (run with -dynamocktest.update to rewrite it)", golden, err)
+		return
+	}
+	gotDB, err := dynamock.NewDBFromReader(bytes.NewReader(got))
+	if err != nil {
+		// e.g. ignored key attributes
+		t.Errorf("dynamocktest: snapshot with ignored attributes: %v", err)
+		return
+	}
+	diff := dynamock.Diff(wantDB, gotDB).String()
+	if diff == "" {
+		diff = "items are equal, table definitions or formats differ\n"
+	}
+	t.Errorf("dynamocktest: snapshot differs from golden file %s (run with -dynamocktest.update to rewrite it):\n%s", golden, diff)
+}
+
+// snap returns the fixture written by write with ignored attributes
+// replaced. Numbers are kept as written, so that they do not lose
+// precision. HTML characters such as the angle brackets of Ignored are
+// not escaped, so that golden files stay readable.
+func snap(write func(io.Writer) error, ignore [][]string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := write(buf); err != nil {
+		return nil, err
+	}
+	jdb := &dynamock.JSONDB{}
+	d := json.NewDecoder(buf)
+	d.UseNumber()
+	if err := d.Decode(jdb); err != nil {
+		return nil, err
+	}
+	for _, jt := range jdb.Tables {
+		typed := jt.Format == "dynamodb"
+		for _, path := range ignore {
+			if !typed {
+				delete(jt.Sets, path[0])
+			}
+			for _, item := range jt.Items {
+				ignoreAttr(item, path, typed)
+			}
+		}
+	}
+	buf.Reset()
+	e := json.NewEncoder(buf)
+	e.SetIndent("", "  ")
+	e.SetEscapeHTML(false)
+	_ = e.Encode(jdb) // encoding decoded JSON cannot fail
+	return buf.Bytes(), nil
+}
+
+// ignoreAttr replaces the attribute at path in the JSON or, if typed,
+// DynamoDB JSON item m.
+func ignoreAttr(m map[string]interface{}, path []string, typed bool) {
+	v, ok := m[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		if typed {
+			m[path[0]] = map[string]interface{}{"S": Ignored}
+		} else {
+			m[path[0]] = Ignored
+		}
+		return
+	}
+	if typed {
+		typedMap, _ := v.(map[string]interface{})
+		v = typedMap["M"]
+	}
+	if sub, ok := v.(map[string]interface{}); ok {
+		ignoreAttr(sub, path[1:], typed)
+	}
+}
+
+func writeGolden(fname string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(fname), 0o755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fname, b, 0o644)
+}
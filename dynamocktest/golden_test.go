@@ -0,0 +1,202 @@
+package dynamocktest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/juliaogris/dynamock"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the errors of failed assertions.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func readDB(t *testing.T) *dynamock.DB {
+	t.Helper()
+	db, err := dynamock.NewDBFromFile(filepath.Join("testdata", "db.json"))
+	require.NoError(t, err)
+	return db
+}
+
+// testUpdate is an -update flag of the test binary, which must not clash
+// with the flag of dynamocktest.
+var testUpdate = flag.Bool("update", false, "rewrite golden files")
+
+var volatile = Ignore("createdAt", "meta.requestID", "at", "missing.attr", "user.name")
+
+func TestAssertDB(t *testing.T) {
+	db := readDB(t)
+	AssertDB(t, db, filepath.Join("testdata", "db.golden.json"), volatile)
+
+	// volatile attributes do not fail assertions
+	_, err := db.PutItem(&dynamodb.PutItemInput{
+		TableName: strPtr("session"),
+		Item: dynamock.Item{
+			"id":        {S: strPtr("b")},
+			"user":      {S: strPtr("bob")},
+			"createdAt": {S: strPtr("2021")},
+			"meta":      {M: dynamock.Item{"requestID": {S: strPtr("0000")}, "client": {S: strPtr("cli")}}},
+		},
+	})
+	require.NoError(t, err)
+	AssertDB(t, db, filepath.Join("testdata", "db.golden.json"), volatile)
+	AssertTable(t, db.Table("event"), filepath.Join("testdata", "event.golden.json"), volatile)
+}
+
+func TestAssertDBMismatch(t *testing.T) {
+	db := readDB(t)
+	_, err := db.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 strPtr("session"),
+		Key:                       dynamock.Item{"id": {S: strPtr("b")}},
+		UpdateExpression:          strPtr("SET #u = :u"),
+		ExpressionAttributeValues: dynamock.Item{":u": {S: strPtr("bea")}},
+		ExpressionAttributeNames:  map[string]*string{"#u": strPtr("user")},
+	})
+	require.NoError(t, err)
+	r := &recorder{}
+	AssertDB(r, db, filepath.Join("testdata", "db.golden.json"), volatile)
+	require.Len(t, r.errs, 1)
+	want := `
+dynamocktest: snapshot differs from golden file testdata/db.golden.json (run with -dynamocktest.update to rewrite it):
+table session
+op, id, attribute, old, new
+ ~,  b,      user, bob, bea
+`[1:]
+	require.Equal(t, want, r.errs[0])
+
+	// equal items in a different format
+	r = &recorder{}
+	AssertDB(r, db.Clone(), filepath.Join("testdata", "db.golden.json"))
+	require.Len(t, r.errs, 1)
+	require.Contains(t, r.errs[0], "table session")
+	r = &recorder{}
+	AssertTable(r, readDB(t).Table("event"), filepath.Join("testdata", "db.golden.json"), volatile)
+	require.Len(t, r.errs, 1)
+	require.Contains(t, r.errs[0], "table session")
+}
+
+func TestAssertTableSchemaMismatch(t *testing.T) {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "event.golden.json"))
+	require.NoError(t, err)
+	f, err := ioutil.TempFile("", "event.golden.json")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.Write(bytes.Replace(b, []byte(`"schema": {`), []byte(`"schema": {"ttlAttribute": "at",`), 1))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	r := &recorder{}
+	AssertTable(r, readDB(t).Table("event"), f.Name(), volatile)
+	require.Len(t, r.errs, 1)
+	require.Contains(t, r.errs[0], "table definitions or formats differ")
+}
+
+func TestAssertDBErr(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dynamocktest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	db := readDB(t)
+
+	r := &recorder{}
+	AssertDB(r, db, filepath.Join(dir, "missing.json"))
+	require.Len(t, r.errs, 1)
+	require.Contains(t, r.errs[0], "run with -dynamocktest.update to create it")
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, ioutil.WriteFile(invalid, []byte("{"), 0o600))
+	r = &recorder{}
+	AssertDB(r, db, invalid)
+	require.Len(t, r.errs, 1)
+	require.Contains(t, r.errs[0], "golden file")
+
+	// ignored key attributes make the items of the snapshot duplicates
+	r = &recorder{}
+	AssertDB(r, db, filepath.Join("testdata", "db.golden.json"), Ignore("id"))
+	require.Len(t, r.errs, 1)
+	require.Contains(t, r.errs[0], "snapshot with ignored attributes")
+
+	r = &recorder{}
+	assertGolden(r, func(io.Writer) error { return errors.New("write failed") }, invalid, nil)
+	assertGolden(r, func(w io.Writer) error { _, err := w.Write([]byte("{")); return err }, invalid, nil)
+	require.Len(t, r.errs, 2)
+	require.Contains(t, r.errs[0], "cannot snapshot: write failed")
+}
+
+func TestAssertDBUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dynamocktest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	*update = true
+	defer func() { *update = false }()
+
+	db := readDB(t)
+	golden := filepath.Join(dir, "golden", "db.json")
+	r := &recorder{}
+	AssertDB(r, db, golden, volatile)
+	require.Empty(t, r.errs)
+	*update = false
+	AssertDB(t, db, golden, volatile)
+	b, err := ioutil.ReadFile(golden)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"createdAt": "<ignored>"`)
+	require.Contains(t, string(b), `"requestID": {`+"\n"+`                "S": "<ignored>"`)
+
+	*update = true
+	AssertDB(r, db, filepath.Join(golden, "db.json"))
+	require.Len(t, r.errs, 1)
+	require.Contains(t, r.errs[0], "cannot update golden file")
+
+	// the -update flag of the test binary
+	*update = false
+	*testUpdate = true
+	defer func() { *testUpdate = false }()
+	golden = filepath.Join(dir, "table.json")
+	AssertTable(t, db.Table("event"), golden, volatile)
+	_, err = os.Stat(golden)
+	require.NoError(t, err)
+}
+
+func TestAssertGoldenNumbers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dynamocktest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	golden := filepath.Join(dir, "numbers.json")
+	fixture := func(n string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, `{"tables": [{"name": "t", "schema": {"primaryKey": {"partitionKey": {"name": "id", "type": "string"}}}, "items": [{"id": "a", "n": %s}]}]}`, n)
+			return err
+		}
+	}
+	*update = true
+	assertGolden(t, fixture("12345678901234567890.123"), golden, nil)
+	*update = false
+	b, err := ioutil.ReadFile(golden)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"n": 12345678901234567890.123`)
+
+	// numbers differing beyond float64 precision fail
+	r := &recorder{}
+	assertGolden(r, fixture("12345678901234567890.124"), golden, nil)
+	require.Len(t, r.errs, 1)
+}
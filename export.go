@@ -0,0 +1,341 @@
+package dynamock
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5" //nolint:gosec // MD5 is used for checksums by the export format
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"foxygo.at/s/errs"
+)
+
+var ErrExport = errors.New("invalid export")
+
+const (
+	manifestSummaryFile = "manifest-summary.json"
+	manifestFilesFile   = "manifest-files.json"
+	exportDataDir       = "data"
+	exportOutputFormat  = "DYNAMODB_JSON"
+)
+
+// exportManifestSummary is the manifest-summary.json file of a DynamoDB
+// export to S3, limited to the fields used by dynamock.
+type exportManifestSummary struct {
+	Version            string `json:"version"`
+	ExportArn          string `json:"exportArn,omitempty"`
+	StartTime          string `json:"startTime,omitempty"`
+	EndTime            string `json:"endTime,omitempty"`
+	TableArn           string `json:"tableArn,omitempty"`
+	ExportTime         string `json:"exportTime,omitempty"`
+	S3Bucket           string `json:"s3Bucket,omitempty"`
+	S3Prefix           string `json:"s3Prefix,omitempty"`
+	ManifestFilesS3Key string `json:"manifestFilesS3Key"`
+	BilledSizeBytes    int64  `json:"billedSizeBytes"`
+	ItemCount          int64  `json:"itemCount"`
+	OutputFormat       string `json:"outputFormat"`
+}
+
+// exportManifestFile is a line of the manifest-files.json file of a
+// DynamoDB export to S3.
+type exportManifestFile struct {
+	ItemCount     int64  `json:"itemCount"`
+	MD5Checksum   string `json:"md5Checksum"`
+	ETag          string `json:"etag,omitempty"`
+	DataFileS3Key string `json:"dataFileS3Key"`
+}
+
+// ReadExport reads items in the DynamoDB JSON lines format of DynamoDB's
+// "Export to S3", one {"Item": {...}} object per line. Gzip compressed
+// input is detected and decompressed.
+func ReadExport(r io.Reader) ([]Item, error) {
+	br := bufio.NewReader(r)
+	r = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, errs.Errorf("%v: %v", ErrExport, err)
+		}
+		r = zr
+	}
+	d := json.NewDecoder(r)
+	items := []Item{}
+	for i := 1; ; i++ {
+		line := struct {
+			Item map[string]interface{}
+		}{}
+		err := d.Decode(&line)
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, errs.Errorf("%v: item %d: %v", ErrExport, i, err)
+		}
+		if line.Item == nil {
+			return nil, errs.Errorf("%v: item %d: %v: Item", ErrExport, i, ErrNil)
+		}
+		item, err := decodeTypedItem(line.Item)
+		if err != nil {
+			return nil, errs.Errorf("%v: item %d: %v", ErrExport, i, err)
+		}
+		items = append(items, item)
+	}
+}
+
+// NewTableFromExport creates a table with the given schema from a DynamoDB
+// export to S3 in DynamoDB JSON format. fpath is either a single, possibly
+// gzipped, data file or an export directory. If the directory contains
+// manifest-files.json, the data files listed there are read from its
+// data subdirectory and their item counts are checked. Otherwise all
+// .json and .json.gz files of the data subdirectory, or of the directory
+// itself, are read in lexical order.
+func NewTableFromExport(name string, schema Schema, fpath string) (*Table, error) {
+	items, err := readExportPath(fpath)
+	if err != nil {
+		return nil, errs.Errorf("NewTableFromExport: %v (table: '%s')", err, name)
+	}
+	table := &Table{name: name, schema: schema, format: formatDynamoDBJSON, items: items}
+	if err := validateTable(table); err != nil {
+		return nil, err
+	}
+	if err := table.index(); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// LoadExport adds a table read with NewTableFromExport to db.
+func (db *DB) LoadExport(name string, schema Schema, fpath string) error {
+	table, err := NewTableFromExport(name, schema, fpath)
+	if err != nil {
+		return err
+	}
+	return db.addTable(table)
+}
+
+func (db *DB) addTable(table *Table) error {
+	db.m.Lock()
+	defer db.m.Unlock()
+	if _, ok := db.tables[table.name]; ok {
+		return errs.Errorf("%v: table '%s'", ErrDuplicate, table.name)
+	}
+	if db.tables == nil {
+		db.tables = map[string]*Table{}
+	}
+	table.clock = db.clock
+	db.tableNames = append(db.tableNames, table.name)
+	db.tables[table.name] = table
+	return nil
+}
+
+func readExportPath(fpath string) ([]Item, error) {
+	fi, err := os.Stat(fpath)
+	if err != nil {
+		return nil, errs.Errorf("%v: %v", ErrExport, err)
+	}
+	if !fi.IsDir() {
+		return readExportFile(fpath)
+	}
+	if manifest, err := ioutil.ReadFile(filepath.Join(fpath, manifestFilesFile)); err == nil {
+		return readExportManifest(fpath, manifest)
+	}
+	dir := fpath
+	if fi, err := os.Stat(filepath.Join(fpath, exportDataDir)); err == nil && fi.IsDir() {
+		dir = filepath.Join(fpath, exportDataDir)
+	}
+	// Glob only fails for malformed patterns.
+	fnames, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	gzFnames, _ := filepath.Glob(filepath.Join(dir, "*.json.gz"))
+	fnames = append(fnames, gzFnames...)
+	sort.Strings(fnames)
+	items := []Item{}
+	for _, fname := range fnames {
+		fileItems, err := readExportFile(fname)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, fileItems...)
+	}
+	return items, nil
+}
+
+func readExportFile(fname string) ([]Item, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, errs.Errorf("%v: %v", ErrExport, err)
+	}
+	defer f.Close()
+	items, err := ReadExport(f)
+	if err != nil {
+		return nil, errs.Errorf("%v (file: '%s')", err, fname)
+	}
+	return items, nil
+}
+
+func readExportManifest(dir string, manifest []byte) ([]Item, error) {
+	var summary *exportManifestSummary
+	if b, err := ioutil.ReadFile(filepath.Join(dir, manifestSummaryFile)); err == nil {
+		summary = &exportManifestSummary{}
+		if err := json.Unmarshal(b, summary); err != nil {
+			return nil, errs.Errorf("%v: %s: %v", ErrExport, manifestSummaryFile, err)
+		}
+		if summary.OutputFormat != exportOutputFormat {
+			return nil, errs.Errorf("%v: %v: output format '%s'", ErrExport, ErrUnimpl, summary.OutputFormat)
+		}
+	}
+	d := json.NewDecoder(bytes.NewReader(manifest))
+	items := []Item{}
+	for {
+		mf := exportManifestFile{}
+		err := d.Decode(&mf)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errs.Errorf("%v: %s: %v", ErrExport, manifestFilesFile, err)
+		}
+		fname := filepath.Join(dir, exportDataDir, path.Base(mf.DataFileS3Key))
+		fileItems, err := readExportFile(fname)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(fileItems)) != mf.ItemCount {
+			return nil, errs.Errorf("%v: %s: expected %d items, got %d", ErrExport, fname, mf.ItemCount, len(fileItems))
+		}
+		items = append(items, fileItems...)
+	}
+	if summary != nil && int64(len(items)) != summary.ItemCount {
+		return nil, errs.Errorf("%v: %s: expected %d items, got %d", ErrExport, manifestSummaryFile, summary.ItemCount, len(items))
+	}
+	return items, nil
+}
+
+// exportFileItems is the maximum number of items per data file written by
+// WriteExport.
+const exportFileItems = 10000
+
+// WriteExport writes the table in the layout of a DynamoDB export to S3 in
+// DynamoDB JSON format: gzipped data files in the data subdirectory of dir,
+// manifest-files.json listing the data files and manifest-summary.json.
+// Data files of an earlier export to dir are removed. The export time is taken from the Clock of the DB of the table, see
+// DB.SetClock.
+func (t *Table) WriteExport(dir string) error {
+	t.m.RLock()
+	clock := t.clock
+	t.m.RUnlock()
+	if clock == nil {
+		clock = realClock{}
+	}
+	return t.writeExport(dir, clock.Now())
+}
+
+// writeExport writes the table as exported at now, see WriteExport.
+func (t *Table) writeExport(dir string, now time.Time) error {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	now = now.UTC()
+	exportID := fmt.Sprintf("%014d-%08x", now.UnixNano()/int64(time.Millisecond), crc32.ChecksumIEEE([]byte(t.name)))
+	prefix := path.Join("AWSDynamoDB", exportID)
+	if err := os.MkdirAll(filepath.Join(dir, exportDataDir), 0o755); err != nil {
+		return errs.Errorf("%v: %v", ErrExport, err)
+	}
+	manifest := &bytes.Buffer{}
+	e := json.NewEncoder(manifest)
+	var billedSize int64
+	written := map[string]bool{}
+	for i := 0; i == 0 || i*exportFileItems < len(t.items); i++ {
+		end := (i + 1) * exportFileItems
+		if end > len(t.items) {
+			end = len(t.items)
+		}
+		items := t.items[i*exportFileItems : end]
+		b := gzipExportItems(items)
+		fname := fmt.Sprintf("%05d.json.gz", i)
+		if err := ioutil.WriteFile(filepath.Join(dir, exportDataDir, fname), b, 0o600); err != nil {
+			return errs.Errorf("%v: %v", ErrExport, err)
+		}
+		written[fname] = true
+		sum := md5.Sum(b) //nolint:gosec
+		mf := exportManifestFile{
+			ItemCount:     int64(len(items)),
+			MD5Checksum:   base64.StdEncoding.EncodeToString(sum[:]),
+			ETag:          hex.EncodeToString(sum[:]),
+			DataFileS3Key: path.Join(prefix, exportDataDir, fname),
+		}
+		_ = e.Encode(mf)
+		for _, item := range items {
+			billedSize += int64(ItemSize(item))
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, manifestFilesFile), manifest.Bytes(), 0o600); err != nil {
+		return errs.Errorf("%v: %v", ErrExport, err)
+	}
+	// A stale data file left by a failed removal is not listed in the
+	// manifest. Glob only fails for malformed patterns.
+	stale, _ := filepath.Glob(filepath.Join(dir, exportDataDir, "*.json.gz"))
+	for _, fname := range stale {
+		if !written[filepath.Base(fname)] {
+			_ = os.Remove(fname)
+		}
+	}
+	tableArn := "arn:aws:dynamodb:us-east-1:000000000000:table/" + t.name
+	timestamp := now.Format("2006-01-02T15:04:05.000Z")
+	summary := exportManifestSummary{
+		Version:            "2020-06-30",
+		ExportArn:          tableArn + "/export/" + exportID,
+		StartTime:          timestamp,
+		EndTime:            timestamp,
+		TableArn:           tableArn,
+		ExportTime:         timestamp,
+		ManifestFilesS3Key: path.Join(prefix, manifestFilesFile),
+		BilledSizeBytes:    billedSize,
+		ItemCount:          int64(len(t.items)),
+		OutputFormat:       exportOutputFormat,
+	}
+	b, _ := json.MarshalIndent(summary, "", "  ")
+	if err := ioutil.WriteFile(filepath.Join(dir, manifestSummaryFile), b, 0o600); err != nil {
+		return errs.Errorf("%v: %v", ErrExport, err)
+	}
+	return nil
+}
+
+// WriteExport writes every table with Table.WriteExport into a
+// subdirectory of dir named after the table. All tables share the same
+// export time.
+func (db *DB) WriteExport(dir string) error {
+	now := db.Clock().Now()
+	for _, table := range db.tableList() {
+		if err := table.writeExport(filepath.Join(dir, table.name), now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gzipExportItems returns the gzipped DynamoDB JSON lines of items. Writes
+// to the in-memory buffer cannot fail.
+func gzipExportItems(items []Item) []byte {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	e := json.NewEncoder(zw)
+	for _, item := range items {
+		line := struct {
+			Item map[string]interface{}
+		}{Item: encodeTypedItem(item)}
+		_ = e.Encode(line)
+	}
+	_ = zw.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,334 @@
+package dynamock
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5" //nolint:gosec
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func productSchema() Schema {
+	return Schema{PrimaryKey: KeyDef{PartitionKey: KeyPartDef{Name: "id", Type: "string"}}}
+}
+
+func TestReadExport(t *testing.T) {
+	r := strings.NewReader(`{"Item":{"id":{"S":"1"},"n":{"N":"1.0"}}}
+{"Item":{"id":{"S":"2"},"b":{"B":"AAE="}}}
+`)
+	items, err := ReadExport(r)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, "1.0", *items[0]["n"].N)
+	require.Equal(t, []byte{0, 1}, items[1]["b"].B)
+
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	_, err = zw.Write([]byte(`{"Item":{"id":{"S":"3"}}}`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	items, err = ReadExport(buf)
+	require.NoError(t, err)
+	require.Equal(t, []Item{{"id": {S: strPtr("3")}}}, items)
+
+	items, err = ReadExport(strings.NewReader(""))
+	require.NoError(t, err)
+	require.Empty(t, items)
+}
+
+func TestReadExportErr(t *testing.T) {
+	testCases := map[string]struct {
+		in   string
+		want error
+	}{
+		"bad_json":    {in: `{"Item":`, want: ErrExport},
+		"missing":     {in: `{"Items":{"id":{"S":"1"}}}`, want: ErrNil},
+		"bad_item":    {in: `{"Item":{"id":"1"}}`, want: ErrInvalidAttr},
+		"bad_gzip":    {in: "\x1f\x8bnot gzip", want: ErrExport},
+		"second_item": {in: "{\"Item\":{\"id\":{\"S\":\"1\"}}}\n{\"Item\":{\"id\":{\"N\":1}}}", want: ErrInvalidType},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := ReadExport(strings.NewReader(tc.in))
+			requireErrIs(t, err, ErrExport)
+			requireErrIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestNewTableFromExportManifest(t *testing.T) {
+	table, err := NewTableFromExport("product", productSchema(), filepath.Join("testdata", "export"))
+	require.NoError(t, err)
+	require.Len(t, table.items, 3)
+	require.Equal(t, "22.50", *table.byPrimary["2"][""]["price"].N)
+	require.Equal(t, "gel", *table.byPrimary["3"][""]["spec"].M["ink"].S)
+	require.Equal(t, strSet("office", "red"), table.byPrimary["1"][""]["tags"])
+}
+
+func TestNewTableFromExportLines(t *testing.T) {
+	dir := filepath.Join("testdata", "export-lines")
+	table, err := NewTableFromExport("product", productSchema(), dir)
+	require.NoError(t, err)
+	require.Len(t, table.items, 3)
+	require.Equal(t, "3", *table.items[2]["id"].S)
+
+	table, err = NewTableFromExport("product", productSchema(), filepath.Join(dir, "2.json.gz"))
+	require.NoError(t, err)
+	require.Len(t, table.items, 1)
+
+	table, err = NewTableFromExport("product", productSchema(), filepath.Join("testdata", "export", "data"))
+	require.NoError(t, err)
+	require.Len(t, table.items, 3)
+
+	// data subdirectory without manifest
+	dir = writeExportFiles(t, map[string]string{"data/a.json": `{"Item":{"id":{"S":"1"}}}`, "b.json": "{"})
+	defer os.RemoveAll(dir)
+	table, err = NewTableFromExport("product", productSchema(), dir)
+	require.NoError(t, err)
+	require.Len(t, table.items, 1)
+}
+
+func TestDBLoadExport(t *testing.T) {
+	db := NewDB()
+	err := db.LoadExport("product", productSchema(), filepath.Join("testdata", "export"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"product"}, db.tableNames)
+	item, err := db.tables["product"].Get(Item{"id": {S: strPtr("1")}})
+	require.NoError(t, err)
+	require.Equal(t, "red pen", *item["name"].S)
+
+	err = db.LoadExport("product", productSchema(), filepath.Join("testdata", "export"))
+	requireErrIs(t, err, ErrDuplicate)
+
+	err = db.LoadExport("product2", productSchema(), filepath.Join("testdata", "MISSING"))
+	requireErrIs(t, err, ErrExport)
+}
+
+func writeExportFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "dynamock-export")
+	require.NoError(t, err)
+	for name, content := range files {
+		fname := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fname), 0o755))
+		require.NoError(t, ioutil.WriteFile(fname, []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestNewTableFromExportErr(t *testing.T) {
+	item := `{"Item":{"id":{"S":"1"}}}` + "\n"
+	manifest := `{"itemCount":1,"md5Checksum":"","dataFileS3Key":"AWSDynamoDB/x/data/a.json"}` + "\n"
+	testCases := map[string]struct {
+		files map[string]string
+		want  error
+	}{
+		"bad_summary": {
+			files: map[string]string{manifestFilesFile: manifest, manifestSummaryFile: "{", "data/a.json": item},
+			want:  ErrExport,
+		},
+		"ion_format": {
+			files: map[string]string{manifestFilesFile: manifest, manifestSummaryFile: `{"outputFormat":"ION"}`, "data/a.json": item},
+			want:  ErrUnimpl,
+		},
+		"summary_count": {
+			files: map[string]string{manifestFilesFile: manifest, manifestSummaryFile: `{"outputFormat":"DYNAMODB_JSON","itemCount":2}`, "data/a.json": item},
+			want:  ErrExport,
+		},
+		"bad_manifest": {
+			files: map[string]string{manifestFilesFile: "{", "data/a.json": item},
+			want:  ErrExport,
+		},
+		"missing_data_file": {
+			files: map[string]string{manifestFilesFile: manifest},
+			want:  ErrExport,
+		},
+		"file_count": {
+			files: map[string]string{manifestFilesFile: manifest, "data/a.json": item + item},
+			want:  ErrExport,
+		},
+		"bad_line": {
+			files: map[string]string{"a.json": item, "b.json.gz": "{"},
+			want:  ErrExport,
+		},
+		"duplicate_key": {
+			files: map[string]string{"a.json": item, "b.json": item},
+			want:  ErrDuplicate,
+		},
+		"missing_key": {
+			files: map[string]string{"a.json": `{"Item":{"x":{"S":"1"}}}`},
+			want:  ErrMissingAttribute,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			dir := writeExportFiles(t, tc.files)
+			defer os.RemoveAll(dir)
+			_, err := NewTableFromExport("product", productSchema(), dir)
+			requireErrIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestWriteExport(t *testing.T) {
+	db := ReadTestdataDB(t, "sets.json")
+	dir, err := ioutil.TempDir("", "dynamock-export")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = db.WriteExport(dir)
+	require.NoError(t, err)
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "post", manifestSummaryFile))
+	require.NoError(t, err)
+	summary := exportManifestSummary{}
+	require.NoError(t, json.Unmarshal(b, &summary))
+	require.Equal(t, int64(3), summary.ItemCount)
+	require.Equal(t, "DYNAMODB_JSON", summary.OutputFormat)
+	require.Equal(t, "arn:aws:dynamodb:us-east-1:000000000000:table/post", summary.TableArn)
+	require.True(t, strings.HasPrefix(summary.ManifestFilesS3Key, "AWSDynamoDB/"))
+	require.True(t, summary.BilledSizeBytes > 0)
+
+	b, err = ioutil.ReadFile(filepath.Join(dir, "post", manifestFilesFile))
+	require.NoError(t, err)
+	mf := exportManifestFile{}
+	require.NoError(t, json.Unmarshal(b, &mf))
+	require.Equal(t, int64(3), mf.ItemCount)
+	require.True(t, strings.HasSuffix(mf.DataFileS3Key, "/data/00000.json.gz"))
+	data, err := ioutil.ReadFile(filepath.Join(dir, "post", "data", "00000.json.gz"))
+	require.NoError(t, err)
+	sum := md5.Sum(data) //nolint:gosec
+	require.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), mf.MD5Checksum)
+
+	table, err := NewTableFromExport("post", db.tables["post"].schema, filepath.Join(dir, "post"))
+	require.NoError(t, err)
+	require.Equal(t, db.tables["post"].items, table.items)
+}
+
+func TestWriteExportClock(t *testing.T) {
+	db := ReadTestdataDB(t, "sets.json")
+	db.SetClock(NewManualClock(epoch))
+	dir, err := ioutil.TempDir("", "dynamock-export")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, db.WriteExport(dir))
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "post", manifestSummaryFile))
+	require.NoError(t, err)
+	summary := exportManifestSummary{}
+	require.NoError(t, json.Unmarshal(b, &summary))
+	require.Equal(t, "2021-01-01T00:00:00.000Z", summary.ExportTime)
+	require.True(t, strings.HasPrefix(summary.ManifestFilesS3Key, "AWSDynamoDB/01609459200000-"), summary.ManifestFilesS3Key)
+
+	// tables use the clock of their DB, also when added or cloned later
+	schema := Schema{PrimaryKey: KeyDef{PartitionKey: KeyPart("id", TypeString)}}
+	require.NoError(t, db.LoadExport("export", schema, filepath.Join(dir, "post")))
+	for _, table := range []*Table{db.Table("post"), db.Table("export"), db.Clone().Table("export")} {
+		tableDir := filepath.Join(dir, "table")
+		require.NoError(t, table.WriteExport(tableDir))
+		b, err = ioutil.ReadFile(filepath.Join(tableDir, manifestSummaryFile))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(b, &summary))
+		require.Equal(t, "2021-01-01T00:00:00.000Z", summary.ExportTime)
+	}
+}
+
+func TestWriteExportEmptyTable(t *testing.T) {
+	db := NewDB()
+	require.NoError(t, db.addTable(&Table{name: "empty", schema: productSchema()}))
+	dir, err := ioutil.TempDir("", "dynamock-export")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, db.WriteExport(dir))
+	table, err := NewTableFromExport("empty", productSchema(), filepath.Join(dir, "empty"))
+	require.NoError(t, err)
+	require.Empty(t, table.items)
+}
+
+func TestWriteExportMultipleFiles(t *testing.T) {
+	table := &Table{name: "product", schema: productSchema()}
+	for i := 0; i < exportFileItems+1; i++ {
+		table.items = append(table.items, Item{"id": {S: strPtr(strconv.Itoa(i))}})
+	}
+	require.NoError(t, table.index())
+	dir, err := ioutil.TempDir("", "dynamock-export")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, table.WriteExport(dir))
+	_, err = os.Stat(filepath.Join(dir, "data", "00001.json.gz"))
+	require.NoError(t, err)
+	table2, err := NewTableFromExport("product", productSchema(), dir)
+	require.NoError(t, err)
+	require.Equal(t, table.items, table2.items)
+
+	// data files of the earlier export are removed
+	table2.items = table2.items[:1]
+	require.NoError(t, table2.index())
+	require.NoError(t, table2.WriteExport(dir))
+	_, err = os.Stat(filepath.Join(dir, "data", "00001.json.gz"))
+	require.True(t, os.IsNotExist(err), err)
+	table3, err := NewTableFromExport("product", productSchema(), filepath.Join(dir, "data"))
+	require.NoError(t, err)
+	require.Len(t, table3.items, 1)
+}
+
+// tickingClock advances by a second whenever it is read.
+type tickingClock struct {
+	Clock // nil, only Now is used
+	now   time.Time
+}
+
+func (c *tickingClock) Now() time.Time {
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func TestWriteExportTime(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	db.SetClock(&tickingClock{now: epoch})
+	dir, err := ioutil.TempDir("", "dynamock-export")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, db.WriteExport(dir))
+
+	for _, name := range db.tableNames {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name, manifestSummaryFile))
+		require.NoError(t, err)
+		summary := exportManifestSummary{}
+		require.NoError(t, json.Unmarshal(b, &summary))
+		require.Equal(t, "2021-01-01T00:00:01.000Z", summary.ExportTime, name)
+	}
+}
+
+func TestWriteExportErr(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	for _, blocked := range []string{"product", "product/data/00000.json.gz", "product/" + manifestFilesFile, "product/" + manifestSummaryFile} {
+		blocked := blocked
+		t.Run(blocked, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "dynamock-export")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			// a directory or file in the way of the export makes writing fail
+			if blocked == "product" {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, blocked), nil, 0o600))
+			} else {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, blocked), 0o755))
+			}
+			err = db.WriteExport(dir)
+			requireErrIs(t, err, ErrExport)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package dynamock
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"foxygo.at/s/errs"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Transient errors of injected faults, see DB.InjectFaults. They are
+// awserr errors with the codes of the DynamoDB service, so that retry
+// logic such as the retryer of the AWS SDK treats them as it treats the
+// errors of the service.
+var (
+	ErrProvisionedThroughputExceeded = requestFailure(dynamodb.ErrCodeProvisionedThroughputExceededException, http.StatusBadRequest)
+	ErrThrottling                    = requestFailure("ThrottlingException", http.StatusBadRequest)
+	ErrInternalServer                = requestFailure(dynamodb.ErrCodeInternalServerError, http.StatusInternalServerError)
+	ErrRequestLimitExceeded          = requestFailure(dynamodb.ErrCodeRequestLimitExceeded, http.StatusBadRequest)
+	ErrTimeout                       = awserr.New("RequestTimeout", "request timed out (injected fault)", nil)
+)
+
+// ErrInvalidFault is returned by DB.InjectFaults for Faults that can never
+// apply.
+var ErrInvalidFault = errors.New("invalid fault")
+
+// faultOps are the operations faults can be injected into, mapped to
+// whether they write.
+var faultOps = map[string]bool{
+	"GetItem":    false,
+	"PutItem":    true,
+	"DeleteItem": true,
+	"Query":      false,
+	"UpdateItem": true,
+}
+
+func requestFailure(code string, status int) error {
+	return awserr.NewRequestFailure(awserr.New(code, "injected fault", nil), status, "")
+}
+
+// Fault is a rule of DB.InjectFaults. Calls match a Fault if they match
+// all of its non-zero Op, Table and Key fields.
+type Fault struct {
+	Op    string // GetItem, PutItem, DeleteItem, Query or UpdateItem, with or without context
+	Table string // table name
+	Key   Item   // key attributes of the item of GetItem, PutItem, DeleteItem and UpdateItem
+
+	After int     // number of matching calls that succeed before the Fault applies
+	Times int     // number of calls that fail at most, 0 for unlimited
+	Rate  float64 // probability that a call fails once the Fault applies, 0 for 1
+
+	// Err is returned by failing calls, e.g. ErrThrottling. A nil Err
+	// defaults to ErrInternalServer, so that failing calls never appear
+	// to succeed.
+	Err error
+	// AfterWrite fails calls after executing them, e.g. for an ambiguous
+	// PutItem failure where the item is written nevertheless. It requires
+	// Op PutItem, DeleteItem or UpdateItem.
+	AfterWrite bool
+}
+
+type faultState struct {
+	Fault
+	matched int
+	failed  int
+}
+
+// faults holds the Fault rules of a DB, see DB.InjectFaults.
+type faults struct {
+	m     sync.Mutex
+	r     *rand.Rand
+	rules []*faultState
+}
+
+// InjectFaults makes calls of GetItem, PutItem, DeleteItem, Query and
+// UpdateItem fail with the Err of the first matching Fault that applies,
+// replacing the faults of a previous InjectFaults call. Fault Rates draw
+// from a random source seeded with seed, so that failures are
+// deterministic for a sequence of calls. InjectFaults returns
+// ErrInvalidFault for a Fault with unknown Op or with AfterWrite for an Op
+// that does not write, and then keeps the faults of db. InjectFaults must
+// not be called concurrently with calls of db.
+func (db *DB) InjectFaults(seed int64, rules ...Fault) error {
+	f := &faults{r: rand.New(rand.NewSource(seed))}
+	for i, rule := range rules {
+		write, ok := faultOps[rule.Op]
+		switch {
+		case rule.Op != "" && !ok:
+			return errs.Errorf("%v: fault %d: unknown operation '%s'", ErrInvalidFault, i, rule.Op)
+		case rule.AfterWrite && !write:
+			return errs.Errorf("%v: fault %d: AfterWrite for operation '%s'", ErrInvalidFault, i, rule.Op)
+		}
+		if rule.Err == nil {
+			rule.Err = ErrInternalServer
+		}
+		f.rules = append(f.rules, &faultState{Fault: rule})
+	}
+	db.faults = f
+	return nil
+}
+
+// ClearFaults removes the faults of InjectFaults.
+func (db *DB) ClearFaults() {
+	db.faults = nil
+}
+
+// fault returns the Fault that applies to a call of op with input in or
+// nil.
+func (db *DB) fault(op string, in interface{}) *Fault {
+	if db.faults == nil {
+		return nil
+	}
+	return db.faults.match(op, tableName(in), inputKey(in))
+}
+
+func (f *faults) match(op, table string, key Item) *Fault {
+	f.m.Lock()
+	defer f.m.Unlock()
+	for _, rule := range f.rules {
+		if !rule.matches(op, table, key) {
+			continue
+		}
+		rule.matched++
+		if rule.matched <= rule.After || (rule.Times != 0 && rule.failed >= rule.Times) {
+			continue
+		}
+		if rule.Rate != 0 && f.r.Float64() >= rule.Rate {
+			continue
+		}
+		rule.failed++
+		return &rule.Fault
+	}
+	return nil
+}
+
+func (rule *faultState) matches(op, table string, key Item) bool {
+	if (rule.Op != "" && rule.Op != op) || (rule.Table != "" && rule.Table != table) {
+		return false
+	}
+	for name, av := range rule.Key {
+		if !attrEqual(av, key[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// inputKey returns the Key or, for PutItem, the Item field of the input
+// in or nil.
+func inputKey(in interface{}) Item {
+	for _, name := range []string{"Key", "Item"} {
+		if f := inputField(in, name); f.IsValid() {
+			return f.Interface().(map[string]*dynamodb.AttributeValue)
+		}
+	}
+	return nil
+}
+
+// inputField returns the field name of the input in or the zero Value.
+func inputField(in interface{}, name string) reflect.Value {
+	v := reflect.ValueOf(in)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return reflect.Value{}
+	}
+	return v.Elem().FieldByName(name)
+}
@@ -0,0 +1,126 @@
+package dynamock
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func getProduct(db *DB, id string) error {
+	_, err := db.GetItem(&dynamodb.GetItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr(id)}}})
+	return err
+}
+
+func TestInjectFaults(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	err := db.InjectFaults(1,
+		Fault{Op: "GetItem", Table: "product", After: 1, Times: 2, Err: ErrThrottling},
+		Fault{Table: "person", Key: Item{"id": {N: strPtr("1")}}, Err: ErrInternalServer},
+		Fault{Op: "Query", Err: ErrTimeout},
+	)
+	require.NoError(t, err)
+	r := db.Record()
+	require.NoError(t, getProduct(db, "1"))
+	requireErrIs(t, getProduct(db, "2"), ErrThrottling)
+	requireErrIs(t, getProduct(db, "1"), ErrThrottling)
+	require.NoError(t, getProduct(db, "1"))
+	requireErrIs(t, r.Calls()[1].Err, ErrThrottling)
+
+	_, err = db.DeleteItem(&dynamodb.DeleteItemInput{TableName: strPtr("person"), Key: Item{"id": {N: strPtr("1.0")}}})
+	requireErrIs(t, err, ErrInternalServer)
+	_, err = db.PutItem(&dynamodb.PutItemInput{TableName: strPtr("person"), Item: Item{"id": {N: strPtr("1")}, "name": {S: strPtr("Jo")}}})
+	requireErrIs(t, err, ErrInternalServer)
+	_, err = db.DeleteItem(&dynamodb.DeleteItemInput{TableName: strPtr("person"), Key: Item{"id": {N: strPtr("2")}}})
+	require.NoError(t, err)
+	item, err := db.Table("person").Get(Item{"id": {N: strPtr("1")}})
+	require.NoError(t, err)
+	require.Equal(t, "Jon", *item["name"].S)
+
+	in := &dynamodb.QueryInput{
+		TableName:                 strPtr("person"),
+		KeyConditionExpression:    strPtr("id = :id"),
+		ExpressionAttributeValues: Item{":id": {N: strPtr("3")}},
+	}
+	_, err = db.Query(in)
+	requireErrIs(t, err, ErrTimeout)
+
+	db.ClearFaults()
+	_, err = db.Query(in)
+	require.NoError(t, err)
+	require.NoError(t, getProduct(db, "2"))
+}
+
+func TestInjectFaultsAfterWrite(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	require.NoError(t, db.InjectFaults(1, Fault{Op: "PutItem", AfterWrite: true, Err: ErrInternalServer}))
+	requireErrIs(t, putProduct(t, db, "5"), ErrInternalServer)
+	_, err := db.Table("product").Get(Item{"id": {S: strPtr("5")}})
+	require.NoError(t, err)
+
+	// the error of the call takes precedence
+	_, err = db.PutItem(&dynamodb.PutItemInput{
+		TableName:           strPtr("product"),
+		Item:                Item{"id": {S: strPtr("5")}},
+		ConditionExpression: strPtr("attribute_not_exists(id)"),
+	})
+	requireErrIs(t, err, ErrConditionalCheckFailed)
+
+	// a nil Err defaults to ErrInternalServer
+	require.NoError(t, db.InjectFaults(1, Fault{Op: "PutItem", AfterWrite: true}))
+	requireErrIs(t, putProduct(t, db, "6"), ErrInternalServer)
+}
+
+func TestInjectFaultsErr(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	require.NoError(t, db.InjectFaults(1, Fault{Op: "GetItem", Err: ErrThrottling}))
+	for _, f := range []Fault{{Op: "Scan"}, {Op: "BatchWriteItem"}, {Op: "Query", AfterWrite: true}, {AfterWrite: true}} {
+		requireErrIs(t, db.InjectFaults(1, f), ErrInvalidFault)
+	}
+	// the faults of db are kept
+	requireErrIs(t, getProduct(db, "1"), ErrThrottling)
+}
+
+func TestInjectFaultsRate(t *testing.T) {
+	failures := func(seed int64) []bool {
+		db := ReadTestdataDB(t, "db.json")
+		require.NoError(t, db.InjectFaults(seed, Fault{Rate: 0.5, Err: ErrProvisionedThroughputExceeded}))
+		var failed []bool
+		for i := 0; i < 50; i++ {
+			failed = append(failed, getProduct(db, "1") != nil)
+		}
+		return failed
+	}
+	got := failures(1)
+	require.Equal(t, got, failures(1))
+	require.NotEqual(t, got, failures(2))
+	require.Contains(t, got, true)
+	require.Contains(t, got, false)
+}
+
+func TestFaultErrors(t *testing.T) {
+	testCases := map[string]struct {
+		err    error
+		code   string
+		status int
+	}{
+		"throughput":    {err: ErrProvisionedThroughputExceeded, code: "ProvisionedThroughputExceededException", status: http.StatusBadRequest},
+		"throttling":    {err: ErrThrottling, code: "ThrottlingException", status: http.StatusBadRequest},
+		"internal":      {err: ErrInternalServer, code: "InternalServerError", status: http.StatusInternalServerError},
+		"request_limit": {err: ErrRequestLimitExceeded, code: "RequestLimitExceeded", status: http.StatusBadRequest},
+		"timeout":       {err: ErrTimeout, code: "RequestTimeout"},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			aerr, ok := tc.err.(awserr.Error)
+			require.True(t, ok)
+			require.Equal(t, tc.code, aerr.Code())
+			if tc.status != 0 {
+				require.Equal(t, tc.status, tc.err.(awserr.RequestFailure).StatusCode())
+			}
+		})
+	}
+}
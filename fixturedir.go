@@ -0,0 +1,264 @@
+package dynamock
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"foxygo.at/s/errs"
+)
+
+// tableFiles are the possible names of the table file in a table
+// directory, see NewDBFromDir.
+var tableFiles = []string{"table.json", "table.yaml", "table.yml"}
+
+// fixtureDirTable collects a table's definition and items across the
+// layers of NewDBFromDir.
+type fixtureDirTable struct {
+	def       *JSONTable
+	defDir    string
+	tableFile string
+	layers    [][]itemFile
+}
+
+type itemFile struct {
+	name  string
+	items []map[string]interface{}
+}
+
+// dirSource records the files of the table directory a table was read
+// from, so that Persist writes items back to the files they came from.
+type dirSource struct {
+	dir       string                // table directory name
+	tableFile string                // table file name
+	itemFiles []string              // item file names in read order
+	files     map[keyStrings]string // item or table file name by primary key
+}
+
+// NewDBFromDir reads fixtures laid out as one subdirectory per table.
+// A table directory contains a table file, table.json, table.yaml or
+// table.yml, with the fields of a fixture table such as schema or
+// createTable, and any number of item files: .json, .yaml and .yml files
+// holding an array of items and .jsonl files holding one item per line.
+// Item files are read in lexical order after the items of the table file.
+// Tables are named after their directory unless the table file names
+// them. Relative createTable paths are resolved against the table
+// directory; a createTable file in the table directory is not read as an
+// item file.
+//
+// Later directories are layered over earlier ones. A table file replaces
+// the table definition of earlier layers, a table directory without table
+// file only adds items. An item replaces the item of an earlier layer
+// with the same primary key in place, other items are appended. Within a
+// layer primary keys must be unique.
+func NewDBFromDir(dirs ...string) (*DB, error) {
+	tables := map[string]*fixtureDirTable{}
+	var names []string
+	for layer, dir := range dirs {
+		fis, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, errs.Errorf("NewDBFromDir: %v", err)
+		}
+		for _, fi := range fis {
+			if !fi.IsDir() {
+				continue
+			}
+			name := fi.Name()
+			dt := tables[name]
+			if dt == nil {
+				dt = &fixtureDirTable{}
+				tables[name] = dt
+				names = append(names, name)
+			}
+			for len(dt.layers) <= layer {
+				dt.layers = append(dt.layers, nil)
+			}
+			if err := dt.read(filepath.Join(dir, name), layer); err != nil {
+				return nil, errs.Errorf("NewDBFromDir: %v (table: '%s')", err, name)
+			}
+		}
+	}
+	db := &DB{tables: map[string]*Table{}}
+	for _, name := range names {
+		table, err := tables[name].newTable(name)
+		if err != nil {
+			return nil, errs.Errorf("NewDBFromDir: %v (table: '%s')", err, name)
+		}
+		if err := db.addTable(table); err != nil {
+			return nil, errs.Errorf("NewDBFromDir: %v", err)
+		}
+	}
+	return db, nil
+}
+
+// read reads the table file and item files of the table directory dir.
+func (dt *fixtureDirTable) read(dir string, layer int) error {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var createTable string
+	for _, fname := range tableFiles {
+		def := &JSONTable{}
+		fname = filepath.Join(dir, fname)
+		err := readFixtureFile(fname, decodeFixtureFile(fname, def))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		dt.def, dt.defDir, dt.tableFile = def, dir, filepath.Base(fname)
+		if def.CreateTable != "" && !filepath.IsAbs(def.CreateTable) {
+			createTable = filepath.Join(dir, def.CreateTable)
+		}
+		dt.layers[layer] = append(dt.layers[layer], itemFile{name: fname, items: def.Items})
+		def.Items = nil
+		break
+	}
+	for _, fi := range fis {
+		if fi.IsDir() || isTableFile(fi.Name()) {
+			continue
+		}
+		fname := filepath.Join(dir, fi.Name())
+		if fname == createTable {
+			continue
+		}
+		var items []map[string]interface{}
+		var decode func(io.Reader) error
+		switch strings.ToLower(filepath.Ext(fname)) {
+		case ".json", ".yaml", ".yml":
+			decode = decodeFixtureFile(fname, &items)
+		case ".jsonl":
+			decode = func(r io.Reader) (err error) {
+				items, err = decodeJSONLines(r)
+				return err
+			}
+		default:
+			continue
+		}
+		if err := readFixtureFile(fname, decode); err != nil {
+			return err
+		}
+		dt.layers[layer] = append(dt.layers[layer], itemFile{name: fname, items: items})
+	}
+	return nil
+}
+
+func isTableFile(name string) bool {
+	for _, tf := range tableFiles {
+		if name == tf {
+			return true
+		}
+	}
+	return false
+}
+
+func readFixtureFile(fname string, decode func(io.Reader) error) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := decode(f); err != nil {
+		return errs.Errorf("%v (file: '%s')", err, fname)
+	}
+	return nil
+}
+
+// decodeFixtureFile returns a decoder into v for JSON, or by any other
+// file extension YAML, files.
+func decodeFixtureFile(fname string, v interface{}) func(io.Reader) error {
+	if strings.ToLower(filepath.Ext(fname)) == ".json" {
+		return func(r io.Reader) error {
+			return json.NewDecoder(r).Decode(v)
+		}
+	}
+	return func(r io.Reader) error {
+		return decodeYAML(r, v)
+	}
+}
+
+func decodeJSONLines(r io.Reader) ([]map[string]interface{}, error) {
+	d := json.NewDecoder(r)
+	var items []map[string]interface{}
+	for {
+		var item map[string]interface{}
+		err := d.Decode(&item)
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+// newTable merges the items of all layers and creates the table. Unless
+// set in the table file, the format is detected per item file and the
+// table is written back in DynamoDB JSON format if any file uses it.
+func (dt *fixtureDirTable) newTable(name string) (*Table, error) {
+	def := dt.def
+	if def == nil {
+		return nil, errs.Errorf("%v: no table file", ErrSchemaValidation)
+	}
+	if err := def.prepare(dt.defDir); err != nil {
+		return nil, err
+	}
+	if def.Name == "" {
+		def.Name = name
+	}
+	if err := validateTable(&Table{name: def.Name, schema: def.Schema}); err != nil {
+		return nil, err
+	}
+	format := def.Format
+	var items []Item
+	pos := map[keyStrings]int{}
+	src := &dirSource{dir: name, tableFile: dt.tableFile, files: map[keyStrings]string{}}
+	for _, layer := range dt.layers {
+		seen := map[keyStrings]bool{}
+		for _, f := range layer {
+			fname := filepath.Base(f.name)
+			if isTableFile(fname) {
+				fname = dt.tableFile
+			} else if !containsStr(src.itemFiles, fname) {
+				src.itemFiles = append(src.itemFiles, fname)
+			}
+			decoded, fileFormat, err := def.decodeItems(f.items)
+			if err != nil {
+				return nil, errs.Errorf("%v (file: '%s')", err, f.name)
+			}
+			if format == "" || fileFormat == formatDynamoDBJSON {
+				format = fileFormat
+			}
+			for _, item := range decoded {
+				if err := validateItem(item, def.Schema); err != nil {
+					return nil, errs.Errorf("%v (file: '%s')", err, f.name)
+				}
+				k, _ := getKeyStrings(item, def.Schema.PrimaryKey)
+				if seen[*k] {
+					return nil, errs.Errorf("%v: %v: partitionKey '%s', sortKey '%s' (file: '%s')", ErrDuplicate, ErrPrimaryKeyVal, k.PartitionKey, k.SortKey, f.name)
+				}
+				seen[*k] = true
+				src.files[*k] = fname
+				if i, ok := pos[*k]; ok {
+					items[i] = item
+					continue
+				}
+				pos[*k] = len(items)
+				items = append(items, item)
+			}
+		}
+	}
+	table, err := def.newTable(items, format)
+	if err != nil {
+		return nil, err
+	}
+	table.source = src
+	return table, nil
+}
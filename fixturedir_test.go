@@ -0,0 +1,128 @@
+package dynamock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDBFromDir(t *testing.T) {
+	db, err := NewDBFromDir(filepath.Join("testdata", "fixturedir", "base"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"order", "person", "product"}, db.tableNames)
+	require.Empty(t, db.tables["order"].items)
+	require.Equal(t, orderSchema().LSIs, db.tables["order"].schema.LSIs)
+
+	person := db.tables["person"]
+	require.Len(t, person.items, 4)
+	require.Equal(t, "Bee", *person.items[3]["name"].S)
+	require.Len(t, person.byIndex["nameGSI"]["Jon"], 2)
+
+	product := db.tables["product"]
+	require.Len(t, product.items, 3)
+	require.Equal(t, formatJSON, product.format)
+}
+
+func TestNewDBFromDirLayers(t *testing.T) {
+	dir := filepath.Join("testdata", "fixturedir")
+	db, err := NewDBFromDir(filepath.Join(dir, "base"), filepath.Join(dir, "override"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"order", "person", "product", "session"}, db.tableNames)
+
+	// replaced items keep their position, new items are appended
+	product := db.tables["product"]
+	require.Len(t, product.items, 4)
+	require.Equal(t, "red pencil", *product.items[0]["name"].S)
+	require.Equal(t, "12", *product.byPrimary["1"][""]["price"].N)
+	require.Equal(t, "black pen", *product.items[3]["name"].S)
+
+	require.Len(t, db.tables["order"].items, 1)
+	session := db.tables["session"]
+	require.Equal(t, formatDynamoDBJSON, session.format)
+	require.Equal(t, "ann", *session.byPrimary["\x00\x01"][""]["user"].S)
+
+	db, err = NewDBFromDir()
+	require.NoError(t, err)
+	require.Empty(t, db.tableNames)
+}
+
+func TestNewDBFromDirTableFileOverride(t *testing.T) {
+	base := writeExportFiles(t, map[string]string{
+		"t/table.json": `{"schema": {"primaryKey": {"partitionKey": {"name": "id", "type": "string"}}}}`,
+		"t/items.json": `[{"id": "1"}]`,
+		"README.md":    "not a table directory",
+	})
+	defer os.RemoveAll(base)
+	override := writeExportFiles(t, map[string]string{
+		"t/table.yml": "name: renamed\nschema: {primaryKey: {partitionKey: {name: id, type: string}, sortKey: {name: n, type: number}}}",
+	})
+	defer os.RemoveAll(override)
+
+	// the replaced schema requires a sort key the base items do not have
+	_, err := NewDBFromDir(base, override)
+	requireErrIs(t, err, ErrMissingAttribute)
+
+	require.NoError(t, os.Remove(filepath.Join(base, "t", "items.json")))
+	db, err := NewDBFromDir(base, override)
+	require.NoError(t, err)
+	require.Equal(t, []string{"renamed"}, db.tableNames)
+	require.Equal(t, "n", db.tables["renamed"].schema.PrimaryKey.SortKey.Name)
+}
+
+func TestNewDBFromDirCreateTable(t *testing.T) {
+	dir := writeExportFiles(t, map[string]string{
+		"t/table.json":  `{"createTable": "create.json"}`,
+		"t/create.json": `{"TableName": "t", "KeySchema": [{"AttributeName": "id", "KeyType": "HASH"}], "AttributeDefinitions": [{"AttributeName": "id", "AttributeType": "S"}]}`,
+		"t/items.json":  `[{"id": "1"}]`,
+	})
+	defer os.RemoveAll(dir)
+	db, err := NewDBFromDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, "id", db.tables["t"].schema.PrimaryKey.PartitionKey.Name)
+	require.Len(t, db.tables["t"].items, 1)
+}
+
+func TestNewDBFromDirErr(t *testing.T) {
+	schema := `{"schema": {"primaryKey": {"partitionKey": {"name": "id", "type": "string"}}}}`
+	testCases := map[string]struct {
+		files map[string]string
+		want  error
+	}{
+		"no_table_file":  {files: map[string]string{"t/items.json": `[]`}, want: ErrSchemaValidation},
+		"bad_table_file": {files: map[string]string{"t/table.json": `{`}},
+		"bad_createTable": {
+			files: map[string]string{"t/table.json": `{"createTable": "missing.json"}`},
+			want:  ErrSchemaValidation,
+		},
+		"bad_schema":  {files: map[string]string{"t/table.yaml": "schema: {}"}, want: ErrSchemaValidation},
+		"bad_json":    {files: map[string]string{"t/table.json": schema, "t/a.json": `{"id": "1"}`}},
+		"bad_yaml":    {files: map[string]string{"t/table.json": schema, "t/a.yaml": `[`}},
+		"bad_jsonl":   {files: map[string]string{"t/table.json": schema, "t/a.jsonl": `{"id": "1"}` + "\n["}},
+		"bad_item":    {files: map[string]string{"t/table.json": schema, "t/a.json": `[{"id": {"S": 1}}]`}, want: ErrInvalidType},
+		"missing_key": {files: map[string]string{"t/table.json": schema, "t/a.json": `[{"x": "1"}]`}, want: ErrMissingAttribute},
+		"duplicate": {
+			files: map[string]string{"t/table.json": schema, "t/a.json": `[{"id": "1"}]`, "t/b.jsonl": `{"id": "1"}`},
+			want:  ErrDuplicate,
+		},
+		"duplicate_name": {
+			files: map[string]string{"t/table.json": schema, "u/table.json": `{"name": "t",` + schema[1:]},
+			want:  ErrDuplicate,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			dir := writeExportFiles(t, tc.files)
+			defer os.RemoveAll(dir)
+			_, err := NewDBFromDir(dir)
+			require.Error(t, err)
+			if tc.want != nil {
+				requireErrIs(t, err, tc.want)
+			}
+		})
+	}
+	_, err := NewDBFromDir(filepath.Join("testdata", "MISSING"))
+	require.Error(t, err)
+}
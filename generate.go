@@ -0,0 +1,242 @@
+package dynamock
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+
+	"foxygo.at/s/errs"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// AttrGen generates an attribute value from the random source r, see
+// Generator.Attr.
+type AttrGen func(r *rand.Rand) *dynamodb.AttributeValue
+
+// Generator generates random items for a schema, e.g. for property based
+// and load tests. Items are deterministic for a given seed, schema and
+// configuration.
+//
+// By default a Generator sets the primary key and the key attributes of
+// secondary indexes only. Partition keys are unique unless Partitions is
+// set; sort keys are ascending sequence numbers, zero padded for string
+// sort keys. Other key attributes are random values of their type. Attr
+// sets generators for further attributes or overrides the default of key
+// attributes.
+type Generator struct {
+	schema     Schema
+	r          *rand.Rand
+	attrs      []attrGen
+	partitions int
+	zipf       *rand.Zipf
+	seq        int
+}
+
+type attrGen struct {
+	name string
+	gen  AttrGen
+}
+
+// NewGenerator returns a Generator for items of schema seeded with seed.
+func NewGenerator(schema Schema, seed int64) *Generator {
+	return &Generator{schema: schema, r: rand.New(rand.NewSource(seed))}
+}
+
+// Attr sets the generator of the attribute name. Attributes are generated
+// in the order of their Attr calls. If gen returns nil, the attribute is
+// omitted, e.g. for optional attributes.
+func (g *Generator) Attr(name string, gen AttrGen) *Generator {
+	for i, a := range g.attrs {
+		if a.name == name {
+			g.attrs[i].gen = gen
+			return g
+		}
+	}
+	g.attrs = append(g.attrs, attrGen{name: name, gen: gen})
+	return g
+}
+
+// Partitions draws partition keys from n distinct values. If skew is
+// greater than 1, the partition key values follow a Zipf distribution
+// with exponent skew, so that few partitions hold most items, as with
+// hot keys. Otherwise they are uniformly distributed. Tables without sort
+// key hold at most n items. Partitions panics if n is negative.
+func (g *Generator) Partitions(n int, skew float64) *Generator {
+	if n < 0 {
+		panic("dynamock: negative n for Generator.Partitions")
+	}
+	g.partitions = n
+	g.zipf = nil
+	if skew > 1 && n > 1 {
+		g.zipf = rand.NewZipf(g.r, skew, 1, uint64(n-1))
+	}
+	return g
+}
+
+// Item returns the next generated item.
+func (g *Generator) Item() Item {
+	item := Item{}
+	pk, sk := g.schema.PrimaryKey.PartitionKey, g.schema.PrimaryKey.SortKey
+	if g.gen(pk.Name) == nil {
+		item[pk.Name] = seqAttr(pk.Type, g.partitionIndex(), "p")
+	}
+	if sk != nil && g.gen(sk.Name) == nil {
+		item[sk.Name] = seqAttr(sk.Type, g.seq, "s")
+	}
+	for _, k := range g.schema.secondaryIndexes() {
+		for _, part := range keyParts(k) {
+			if item[part.Name] == nil && g.gen(part.Name) == nil {
+				item[part.Name] = randomAttr(g.r, part.Type)
+			}
+		}
+	}
+	for _, a := range g.attrs {
+		if av := a.gen(g.r); av != nil {
+			item[a.name] = av
+		}
+	}
+	g.seq++
+	return item
+}
+
+// Items returns the next n generated items.
+func (g *Generator) Items(n int) []Item {
+	items := make([]Item, n)
+	for i := range items {
+		items[i] = g.Item()
+	}
+	return items
+}
+
+// Load adds n generated items to t like Put, replacing items with the
+// same primary key. Items are indexed once all are added, so that millions of items
+// can be loaded for load tests. If a generated item is invalid, e.g. due
+// to an Attr generator of a key attribute, no items are added.
+func (g *Generator) Load(t *Table, n int) error {
+	t.m.Lock()
+	defer t.m.Unlock()
+	pos := make(map[keyStrings]int, len(t.items)+n)
+	for i, item := range t.items {
+		k, _ := getKeyStrings(item, t.schema.PrimaryKey)
+		pos[*k] = i
+	}
+	items := append([]Item(nil), t.items...)
+	for i := 0; i < n; i++ {
+		item := g.Item()
+		if err := validateItem(item, t.schema); err != nil {
+			return errs.Errorf("%v (generated item: %d)", err, i)
+		}
+		k, _ := getKeyStrings(item, t.schema.PrimaryKey)
+		if j, ok := pos[*k]; ok {
+			items[j] = nil
+		}
+		pos[*k] = len(items)
+		items = append(items, item)
+	}
+	t.items = make([]Item, 0, len(pos))
+	for _, item := range items {
+		if item != nil {
+			t.items = append(t.items, item)
+		}
+	}
+	t.shared = false
+	return t.index()
+}
+
+func (g *Generator) gen(name string) AttrGen {
+	for _, a := range g.attrs {
+		if a.name == name {
+			return a.gen
+		}
+	}
+	return nil
+}
+
+func (g *Generator) partitionIndex() int {
+	switch {
+	case g.zipf != nil:
+		return int(g.zipf.Uint64())
+	case g.partitions > 0:
+		return g.r.Intn(g.partitions)
+	}
+	return g.seq
+}
+
+// seqAttr returns the i-th key value of type typ. Strings are prefixed
+// and zero padded so that they sort like numbers.
+func seqAttr(typ string, i int, prefix string) *dynamodb.AttributeValue {
+	switch typ {
+	case "string":
+		s := fmt.Sprintf("%s%09d", prefix, i)
+		return &dynamodb.AttributeValue{S: &s}
+	case "binary":
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, uint64(i))
+		return &dynamodb.AttributeValue{B: b}
+	}
+	s := strconv.Itoa(i)
+	return &dynamodb.AttributeValue{N: &s}
+}
+
+func randomAttr(r *rand.Rand, typ string) *dynamodb.AttributeValue {
+	switch typ {
+	case "string":
+		return RandomString(8)(r)
+	case "binary":
+		b := make([]byte, 8)
+		_, _ = r.Read(b)
+		return &dynamodb.AttributeValue{B: b}
+	}
+	return IntRange(0, 999)(r)
+}
+
+// IntRange generates numbers uniformly distributed in [min, max]. It
+// panics if max is less than min.
+func IntRange(min, max int64) AttrGen {
+	if max < min {
+		panic("dynamock: max less than min for IntRange")
+	}
+	return func(r *rand.Rand) *dynamodb.AttributeValue {
+		s := strconv.FormatInt(min+r.Int63n(max-min+1), 10)
+		return &dynamodb.AttributeValue{N: &s}
+	}
+}
+
+// NormalInt generates integers normally distributed around mean, e.g. for
+// sort keys clustered around a point in time.
+func NormalInt(mean, stddev float64) AttrGen {
+	return func(r *rand.Rand) *dynamodb.AttributeValue {
+		s := strconv.FormatInt(int64(math.Round(r.NormFloat64()*stddev+mean)), 10)
+		return &dynamodb.AttributeValue{N: &s}
+	}
+}
+
+// RandomString generates strings of n random lower case letters. It
+// panics if n is negative.
+func RandomString(n int) AttrGen {
+	if n < 0 {
+		panic("dynamock: negative n for RandomString")
+	}
+	return func(r *rand.Rand) *dynamodb.AttributeValue {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte('a' + r.Intn(26))
+		}
+		s := string(b)
+		return &dynamodb.AttributeValue{S: &s}
+	}
+}
+
+// OneOf generates one of values, uniformly distributed. It panics if
+// values is empty.
+func OneOf(values ...string) AttrGen {
+	if len(values) == 0 {
+		panic("dynamock: no values for OneOf")
+	}
+	return func(r *rand.Rand) *dynamodb.AttributeValue {
+		s := values[r.Intn(len(values))]
+		return &dynamodb.AttributeValue{S: &s}
+	}
+}
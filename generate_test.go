@@ -0,0 +1,151 @@
+package dynamock
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func generatorSchema() Schema {
+	return Schema{
+		PrimaryKey: KeyDef{PartitionKey: KeyPart("pk", TypeString), SortKey: &KeyPartDef{Name: "sk", Type: TypeNumber}},
+		GSIs: []KeyDef{
+			{Name: "byName", PartitionKey: KeyPart("name", TypeString), SortKey: &KeyPartDef{Name: "rank", Type: TypeNumber}},
+			{Name: "byTag", PartitionKey: KeyPart("tag", TypeNumber), SortKey: &KeyPartDef{Name: "blob", Type: TypeBinary}},
+			{Name: "byColor", PartitionKey: KeyPart("color", TypeString)},
+		},
+		LSIs: []KeyDef{{Name: "byTitle", PartitionKey: KeyPart("pk", TypeString), SortKey: &KeyPartDef{Name: "title", Type: TypeString}}},
+	}
+}
+
+func newTestGenerator(seed int64) *Generator {
+	return NewGenerator(generatorSchema(), seed).
+		Partitions(5, 0).
+		Attr("rank", IntRange(0, 3)).
+		Attr("tag", IntRange(0, 1)).
+		Attr("blob", func(r *rand.Rand) *dynamodb.AttributeValue {
+			return &dynamodb.AttributeValue{B: []byte{byte(r.Intn(2))}}
+		}).
+		Attr("color", OneOf("red", "blue")).
+		Attr("title", OneOf("a", "b")).
+		Attr("score", NormalInt(100, 10)).
+		Attr("note", func(r *rand.Rand) *dynamodb.AttributeValue { return nil })
+}
+
+func TestGenerator(t *testing.T) {
+	items := newTestGenerator(1).Items(100)
+	require.Equal(t, items, newTestGenerator(1).Items(100))
+	require.NotEqual(t, items, newTestGenerator(2).Items(100))
+
+	partitions := map[string]bool{}
+	for _, item := range items {
+		require.NoError(t, validateItem(item, generatorSchema()))
+		require.Nil(t, item["note"])
+		partitions[*item["pk"].S] = true
+	}
+	require.Len(t, partitions, 5)
+	require.Equal(t, "p000000000", *NewGenerator(generatorSchema(), 1).Item()["pk"].S)
+
+	// attribute generators can be replaced
+	g := NewGenerator(generatorSchema(), 1).Attr("color", OneOf("red")).Attr("color", OneOf("green"))
+	require.Equal(t, "green", *g.Item()["color"].S)
+}
+
+func TestGeneratorDefaults(t *testing.T) {
+	schema := Schema{
+		PrimaryKey: KeyDef{PartitionKey: KeyPart("pk", TypeBinary), SortKey: &KeyPartDef{Name: "sk", Type: TypeString}},
+		GSIs:       []KeyDef{{Name: "g", PartitionKey: KeyPart("a", TypeString), SortKey: &KeyPartDef{Name: "b", Type: TypeBinary}}},
+	}
+	items := NewGenerator(schema, 1).Items(3)
+	require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 2}, items[2]["pk"].B)
+	require.Equal(t, "s000000002", *items[2]["sk"].S)
+	require.Len(t, *items[2]["a"].S, 8)
+	require.Len(t, items[2]["b"].B, 8)
+
+	schema = Schema{PrimaryKey: KeyDef{PartitionKey: KeyPart("pk", TypeNumber)}}
+	require.Equal(t, "2", *NewGenerator(schema, 1).Items(3)[2]["pk"].N)
+}
+
+func TestGeneratorSkew(t *testing.T) {
+	schema := Schema{PrimaryKey: KeyDef{PartitionKey: KeyPart("pk", TypeNumber), SortKey: &KeyPartDef{Name: "sk", Type: TypeNumber}}}
+	count := func(g *Generator) int {
+		hot := 0
+		for _, item := range g.Items(1000) {
+			if *item["pk"].N == "0" {
+				hot++
+			}
+		}
+		return hot
+	}
+	uniform := count(NewGenerator(schema, 1).Partitions(100, 0))
+	skewed := count(NewGenerator(schema, 1).Partitions(100, 2))
+	require.Less(t, uniform, 50)
+	require.Greater(t, skewed, 500)
+}
+
+func TestGeneratorLoad(t *testing.T) {
+	schema := generatorSchema()
+	loaded := &Table{name: "t", schema: schema}
+	require.NoError(t, loaded.index())
+	require.NoError(t, newTestGenerator(1).Load(loaded, 200))
+	require.NoError(t, newTestGenerator(2).Load(loaded, 200))
+
+	// loading indexes like putting items one by one
+	put := &Table{name: "t", schema: schema}
+	require.NoError(t, put.index())
+	for _, g := range []*Generator{newTestGenerator(1), newTestGenerator(2)} {
+		for _, item := range g.Items(200) {
+			_, err := put.Put(item)
+			require.NoError(t, err)
+		}
+	}
+	require.Equal(t, len(put.items), len(loaded.items))
+	require.Equal(t, put.byPrimary, loaded.byPrimary)
+	require.Equal(t, nonEmpty(put.byIndex), nonEmpty(loaded.byIndex))
+	require.Less(t, len(loaded.items), 400) // replaced primary keys
+
+	// shared items are copied before loading
+	db := NewDB()
+	require.NoError(t, db.addTable(loaded))
+	cp := db.Checkpoint()
+	require.NoError(t, newTestGenerator(3).Load(loaded, 10))
+	n := len(loaded.items)
+	require.NoError(t, db.Rollback(cp))
+	require.Less(t, len(loaded.items), n)
+}
+
+func TestGeneratorLoadErr(t *testing.T) {
+	table := &Table{name: "t", schema: generatorSchema()}
+	require.NoError(t, table.index())
+	g := newTestGenerator(1).Attr("pk", IntRange(0, 9))
+	err := g.Load(table, 10)
+	requireErrIs(t, err, ErrPrimaryKeyVal)
+	require.Contains(t, err.Error(), "(generated item: 0)")
+	require.Empty(t, table.items)
+	require.Equal(t, generatorSchema().PrimaryKey, table.Schema().PrimaryKey)
+}
+
+func TestGeneratorInvalidArgs(t *testing.T) {
+	require.Panics(t, func() { OneOf() })
+	require.Panics(t, func() { IntRange(1, 0) })
+	require.Panics(t, func() { RandomString(-1) })
+	require.Panics(t, func() { newTestGenerator(1).Partitions(-1, 0) })
+	require.Equal(t, "7", *IntRange(7, 7)(rand.New(rand.NewSource(1))).N)
+}
+
+// nonEmpty returns byIndex without the empty partitions left behind by
+// replaced items.
+func nonEmpty(byIndex map[string]map[string][]Item) map[string]map[string][]Item {
+	result := map[string]map[string][]Item{}
+	for name, partitions := range byIndex {
+		result[name] = map[string][]Item{}
+		for k, items := range partitions {
+			if len(items) != 0 {
+				result[name][k] = items
+			}
+		}
+	}
+	return result
+}
@@ -6,4 +6,5 @@ require (
 	foxygo.at/s v0.0.7
 	github.com/aws/aws-sdk-go v1.30.7
 	github.com/stretchr/testify v1.5.1
+	gopkg.in/yaml.v3 v3.0.1
 )
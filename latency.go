@@ -0,0 +1,161 @@
+package dynamock
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// LatencyDist draws a latency from the random source r, see Latency.
+type LatencyDist func(r *rand.Rand) time.Duration
+
+// Latency is the latency model of DB.SimulateLatency.
+type Latency struct {
+	// Ops holds the latency distributions of operations, e.g. "Query".
+	Ops map[string]LatencyDist
+	// Default is the latency distribution of operations without Ops
+	// entry; nil for no latency.
+	Default LatencyDist
+	// PerItem is added for every item a Query reads, before filtering.
+	PerItem time.Duration
+}
+
+// latency is the Latency of a DB with its random source.
+type latency struct {
+	Latency
+	m sync.Mutex
+	r *rand.Rand
+}
+
+// SimulateLatency makes calls of GetItem, PutItem, DeleteItem, Query and
+// UpdateItem take the latency of l on the Clock of db, see SetClock. The
+// latency distributions draw from a random source seeded with seed.
+// Context deadlines expire on the Clock as well as in real time. A zero
+// Latency disables latency simulation. SimulateLatency must not be
+// called concurrently with calls of db.
+func (db *DB) SimulateLatency(seed int64, l Latency) {
+	db.latency = &latency{Latency: l, r: rand.New(rand.NewSource(seed))}
+}
+
+// FixedLatency is a LatencyDist of constant latency d.
+func FixedLatency(d time.Duration) LatencyDist {
+	return func(*rand.Rand) time.Duration { return d }
+}
+
+// UniformLatency is a LatencyDist of latencies uniformly distributed in
+// [min, max), or of constant latency min if max is not greater than min.
+func UniformLatency(min, max time.Duration) LatencyDist {
+	if max <= min {
+		return FixedLatency(min)
+	}
+	return func(r *rand.Rand) time.Duration {
+		return min + time.Duration(r.Int63n(int64(max-min)))
+	}
+}
+
+// LogNormalLatency is a LatencyDist of latencies log-normally distributed
+// around median, with a long tail for sigma > 0, as typical for network
+// latency.
+func LogNormalLatency(median time.Duration, sigma float64) LatencyDist {
+	return func(r *rand.Rand) time.Duration {
+		return time.Duration(float64(median) * math.Exp(r.NormFloat64()*sigma))
+	}
+}
+
+// duration returns the latency of a call of op.
+func (l *latency) duration(op string) time.Duration {
+	if l == nil {
+		return 0
+	}
+	dist, ok := l.Ops[op]
+	if !ok {
+		dist = l.Default
+	}
+	if dist == nil {
+		return 0
+	}
+	l.m.Lock()
+	defer l.m.Unlock()
+	return dist(l.r)
+}
+
+// itemsDuration returns the per item latency of the output out.
+func (l *latency) itemsDuration(out interface{}) time.Duration {
+	q, ok := out.(*dynamodb.QueryOutput)
+	if l == nil || !ok {
+		return 0
+	}
+	n := int64(len(q.Items))
+	if q.ScannedCount != nil {
+		n = *q.ScannedCount
+	}
+	return time.Duration(n) * l.PerItem
+}
+
+// call runs the operation op with input in by calling fn. It honors the
+// cancellation and deadline of ctx, simulates latency, injects faults and
+// records the call.
+func (db *DB) call(ctx aws.Context, op string, in interface{}, fn func() (interface{}, error)) (interface{}, error) {
+	clock := db.Clock()
+	start := clock.Now()
+	out, err := db.run(ctx, op, in, fn)
+	return out, db.record(op, in, out, err, clock.Now().Sub(start))
+}
+
+func (db *DB) run(ctx aws.Context, op string, in interface{}, fn func() (interface{}, error)) (interface{}, error) {
+	if err := db.sleep(ctx, db.latency.duration(op)); err != nil {
+		return nil, err
+	}
+	fault := db.fault(op, in)
+	if fault != nil && !fault.AfterWrite {
+		return nil, fault.Err
+	}
+	out, err := fn()
+	if err != nil {
+		return out, err
+	}
+	if fault != nil {
+		return nil, fault.Err
+	}
+	if d := db.latency.itemsDuration(out); d > 0 {
+		if err := db.sleep(ctx, d); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// sleep waits for d on the Clock of db. It returns a RequestCanceled
+// error, as the AWS SDK does, if ctx is done, or if the deadline of ctx
+// passes on the Clock before d elapses.
+func (db *DB) sleep(ctx aws.Context, d time.Duration) error {
+	if d > 0 {
+		clock := db.Clock()
+		expires := false
+		if deadline, ok := ctx.Deadline(); ok {
+			if left := deadline.Sub(clock.Now()); left < d {
+				d, expires = left, true
+			}
+		}
+		if d > 0 {
+			select {
+			case <-ctx.Done():
+			case <-clock.After(d):
+			}
+		}
+		if expires && ctx.Err() == nil {
+			return awserr.New(request.CanceledErrorCode, "request context canceled", context.DeadlineExceeded)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+	return nil
+}
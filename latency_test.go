@@ -0,0 +1,190 @@
+package dynamock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+// instantClock elapses waits of After immediately.
+type instantClock struct {
+	Clock // nil, latency only uses Now and After
+	now   time.Time
+	slept []time.Duration
+	after func(n int) // called before the n-th wait, counting from 0
+}
+
+func (c *instantClock) Now() time.Time {
+	return c.now
+}
+
+func (c *instantClock) After(d time.Duration) <-chan time.Time {
+	if c.after != nil {
+		c.after(len(c.slept))
+	}
+	c.now = c.now.Add(d)
+	c.slept = append(c.slept, d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// blockingClock never elapses waits of After.
+type blockingClock struct{ instantClock }
+
+func (*blockingClock) After(time.Duration) <-chan time.Time {
+	return nil
+}
+
+func queryPerson(ctx context.Context, db *DB, sel *string) (*dynamodb.QueryOutput, error) {
+	return db.QueryWithContext(ctx, &dynamodb.QueryInput{
+		TableName:                 strPtr("person"),
+		KeyConditionExpression:    strPtr("id = :id"),
+		ExpressionAttributeValues: Item{":id": {N: strPtr("1")}},
+		Select:                    sel,
+	})
+}
+
+func TestSimulateLatency(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	clock := &instantClock{}
+	db.SetClock(clock)
+	db.SimulateLatency(1, Latency{
+		Default: FixedLatency(10 * time.Millisecond),
+		Ops:     map[string]LatencyDist{"Query": UniformLatency(20*time.Millisecond, 30*time.Millisecond), "DeleteItem": nil},
+		PerItem: time.Millisecond,
+	})
+	r := db.Record()
+	require.NoError(t, getProduct(db, "1"))
+	_, err := queryPerson(context.Background(), db, nil)
+	require.NoError(t, err)
+	_, err = queryPerson(context.Background(), db, strPtr("COUNT"))
+	require.NoError(t, err)
+	_, err = db.DeleteItem(&dynamodb.DeleteItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr("1")}}})
+	require.NoError(t, err)
+
+	require.Len(t, clock.slept, 5)
+	require.Equal(t, 10*time.Millisecond, clock.slept[0])
+	require.True(t, clock.slept[1] >= 20*time.Millisecond && clock.slept[1] < 30*time.Millisecond, clock.slept[1])
+	require.Equal(t, time.Millisecond, clock.slept[2])
+	require.Equal(t, time.Millisecond, clock.slept[4])
+	calls := r.Calls()
+	require.Equal(t, clock.slept[1]+clock.slept[2], calls[1].Duration)
+	require.Equal(t, time.Duration(0), calls[3].Duration)
+
+	// latencies are deterministic for a seed
+	db2 := ReadTestdataDB(t, "db.json")
+	clock2 := &instantClock{}
+	db2.SetClock(clock2)
+	db2.SimulateLatency(1, Latency{Ops: map[string]LatencyDist{"Query": UniformLatency(20*time.Millisecond, 30*time.Millisecond)}})
+	_, err = queryPerson(context.Background(), db2, nil)
+	require.NoError(t, err)
+	require.Equal(t, clock.slept[1:2], clock2.slept)
+	require.Same(t, clock2, db2.Clone().Clock())
+
+	db2.SimulateLatency(1, Latency{})
+	require.NoError(t, getProduct(db2, "1"))
+	require.Len(t, clock2.slept, 1)
+
+	// empty ranges are constant
+	require.Equal(t, time.Second, UniformLatency(time.Second, time.Second)(nil))
+	require.Equal(t, time.Second, UniformLatency(time.Second, 0)(nil))
+}
+
+func TestLogNormalLatency(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	clock := &instantClock{}
+	db.SetClock(clock)
+	db.SimulateLatency(1, Latency{Default: LogNormalLatency(10*time.Millisecond, 0.5)})
+	var below int
+	for i := 0; i < 100; i++ {
+		require.NoError(t, getProduct(db, "1"))
+		if clock.slept[i] < 10*time.Millisecond {
+			below++
+		}
+	}
+	require.True(t, below > 30 && below < 70, below)
+
+	db.SimulateLatency(1, Latency{Default: LogNormalLatency(10*time.Millisecond, 0)})
+	require.NoError(t, getProduct(db, "1"))
+	require.Equal(t, 10*time.Millisecond, clock.slept[100])
+}
+
+func requireCanceled(t *testing.T, err, want error) {
+	t.Helper()
+	aerr, ok := err.(awserr.Error)
+	require.True(t, ok, err)
+	require.Equal(t, "RequestCanceled", aerr.Code())
+	require.Equal(t, want, aerr.OrigErr())
+}
+
+func TestContext(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := db.PutItemWithContext(canceled, &dynamodb.PutItemInput{TableName: strPtr("product"), Item: Item{"id": {S: strPtr("5")}}})
+	requireCanceled(t, err, context.Canceled)
+	item, err := db.Table("product").Get(Item{"id": {S: strPtr("5")}})
+	require.NoError(t, err)
+	require.Nil(t, item)
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err = db.GetItemWithContext(expired, &dynamodb.GetItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr("1")}}})
+	requireCanceled(t, err, context.DeadlineExceeded)
+
+	// cancellation during simulated latency
+	db.SetClock(&blockingClock{})
+	db.SimulateLatency(1, Latency{Default: FixedLatency(time.Hour)})
+	_, err = db.DeleteItemWithContext(canceled, &dynamodb.DeleteItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr("1")}}})
+	requireCanceled(t, err, context.Canceled)
+
+	// cancellation during the per item latency of a Query
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := &instantClock{after: func(n int) {
+		if n == 1 {
+			cancel()
+		}
+	}}
+	db.SetClock(clock)
+	db.SimulateLatency(1, Latency{Default: FixedLatency(time.Second), PerItem: time.Second})
+	out, err := queryPerson(ctx, db, nil)
+	requireCanceled(t, err, context.Canceled)
+	require.Nil(t, out)
+}
+
+func TestContextDeadlineManualClock(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	clock := NewManualClock(time.Now())
+	db.SetClock(clock)
+	db.SimulateLatency(1, Latency{Default: FixedLatency(time.Hour)})
+	r := db.Record()
+
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(time.Minute))
+	defer cancel()
+	get := func() error {
+		_, err := db.GetItemWithContext(ctx, &dynamodb.GetItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr("1")}}})
+		return err
+	}
+	done := make(chan error)
+	go func() { done <- get() }()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	requireCanceled(t, <-done, context.DeadlineExceeded)
+	require.Equal(t, time.Minute, r.Calls()[0].Duration)
+
+	// the deadline has passed on the clock
+	clock.Advance(time.Minute)
+	requireCanceled(t, get(), context.DeadlineExceeded)
+}
+
+func TestRealClock(t *testing.T) {
+	db := NewDB()
+	require.Equal(t, realClock{}, db.Clock())
+	require.WithinDuration(t, time.Now(), db.Clock().Now(), time.Minute)
+	<-db.Clock().After(0)
+}
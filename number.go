@@ -0,0 +1,169 @@
+package dynamock
+
+import (
+	"errors"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"foxygo.at/s/errs"
+)
+
+var (
+	ErrInvalidNumber = errors.New("invalid number")
+
+	reNumber = regexp.MustCompile(`^([+-]?)([0-9]*)(?:\.([0-9]*))?(?:[eE]([+-]?[0-9]+))?$`)
+)
+
+const (
+	maxNumberDigits = 38
+	// A Number's value is 0.digits * 10^exp, DynamoDB supports positive
+	// and negative values from 1E-130 to 9.9999999999999999999999999999999999999E+125.
+	minNumberExp = -129
+	maxNumberExp = 126
+)
+
+// Number is a decimal number with DynamoDB's precision of up to 38
+// significant digits and its range of magnitudes from 1E-130 to
+// 9.9999999999999999999999999999999999999E+125.
+type Number struct {
+	neg    bool
+	digits string // significant digits without leading or trailing zeros, empty for zero
+	exp    int
+}
+
+// ParseNumber parses the string representation of a DynamoDB number
+// attribute value, e.g. "12", "-0.5" or "1.5E-7".
+func ParseNumber(s string) (Number, error) {
+	m := reNumber.FindStringSubmatch(s)
+	if m == nil || m[2]+m[3] == "" {
+		return Number{}, errs.Errorf("%v: '%s'", ErrInvalidNumber, s)
+	}
+	exp := 0
+	if m[4] != "" {
+		var err error
+		if exp, err = strconv.Atoi(m[4]); err != nil {
+			return Number{}, errs.Errorf("%v: '%s': exponent out of range", ErrInvalidNumber, s)
+		}
+	}
+	digits := m[2] + m[3]
+	exp += len(m[2])
+	trimmed := strings.TrimLeft(digits, "0")
+	exp -= len(digits) - len(trimmed)
+	digits = strings.TrimRight(trimmed, "0")
+	if digits == "" {
+		return Number{}, nil
+	}
+	if len(digits) > maxNumberDigits {
+		return Number{}, errs.Errorf("%v: '%s': more than %d significant digits", ErrInvalidNumber, s, maxNumberDigits)
+	}
+	if exp < minNumberExp || exp > maxNumberExp {
+		return Number{}, errs.Errorf("%v: '%s': out of range", ErrInvalidNumber, s)
+	}
+	return Number{neg: m[1] == "-", digits: digits, exp: exp}, nil
+}
+
+// String returns the canonical decimal representation of n, without
+// exponent and without leading or trailing zeros.
+func (n Number) String() string {
+	if n.digits == "" {
+		return "0"
+	}
+	var s string
+	switch l := len(n.digits); {
+	case n.exp <= 0:
+		s = "0." + strings.Repeat("0", -n.exp) + n.digits
+	case n.exp >= l:
+		s = n.digits + strings.Repeat("0", n.exp-l)
+	default:
+		s = n.digits[:n.exp] + "." + n.digits[n.exp:]
+	}
+	if n.neg {
+		return "-" + s
+	}
+	return s
+}
+
+// Cmp compares n and m and returns -1 if n < m, 0 if n == m and +1 if n > m.
+func (n Number) Cmp(m Number) int {
+	if sn, sm := n.sign(), m.sign(); sn != sm {
+		if sn < sm {
+			return -1
+		}
+		return 1
+	}
+	c := n.cmpAbs(m)
+	if n.neg {
+		return -c
+	}
+	return c
+}
+
+func (n Number) sign() int {
+	switch {
+	case n.digits == "":
+		return 0
+	case n.neg:
+		return -1
+	}
+	return 1
+}
+
+func (n Number) cmpAbs(m Number) int {
+	switch {
+	case n.exp < m.exp:
+		return -1
+	case n.exp > m.exp:
+		return 1
+	}
+	// digits have no trailing zeros, so lexical order is numeric order.
+	return strings.Compare(n.digits, m.digits)
+}
+
+// Add returns the sum of n and m. It fails if the result exceeds
+// DynamoDB's precision or range.
+func (n Number) Add(m Number) (Number, error) {
+	c1, scale1 := n.coefficient()
+	c2, scale2 := m.coefficient()
+	// align the scales of both coefficients to the smaller one
+	scale := scale1
+	if scale2 < scale {
+		scale = scale2
+	}
+	c1.Mul(c1, pow10(scale1-scale))
+	c2.Mul(c2, pow10(scale2-scale))
+	sum := c1.Add(c1, c2)
+	return ParseNumber(sum.String() + "E" + strconv.Itoa(scale))
+}
+
+// coefficient returns c and scale with n = c * 10^scale.
+func (n Number) coefficient() (*big.Int, int) {
+	c := big.NewInt(0)
+	if n.digits == "" {
+		return c, 0
+	}
+	c.SetString(n.digits, 10)
+	if n.neg {
+		c.Neg(c)
+	}
+	return c, n.exp - len(n.digits)
+}
+
+func pow10(e int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(e)), nil)
+}
+
+// cmpNumberStrings compares two DynamoDB number strings, ok is false if
+// either string is not a valid number.
+func cmpNumberStrings(s1, s2 string) (c int, ok bool) {
+	n1, err := ParseNumber(s1)
+	if err != nil {
+		return 0, false
+	}
+	n2, err := ParseNumber(s2)
+	if err != nil {
+		return 0, false
+	}
+	return n1.Cmp(n2), true
+}
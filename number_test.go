@@ -0,0 +1,132 @@
+package dynamock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNumber(t *testing.T) {
+	testCases := map[string]string{
+		"0":        "0",
+		"-0":       "0",
+		"0.000":    "0",
+		"1":        "1",
+		"+1":       "1",
+		"1.0":      "1",
+		"001.500":  "1.5",
+		".5":       "0.5",
+		"5.":       "5",
+		"-12.34":   "-12.34",
+		"1E3":      "1000",
+		"1.5e-3":   "0.0015",
+		"-2.5E+2":  "-250",
+		"120e-1":   "12",
+		"0.000123": "0.000123",
+	}
+	testCases[strings.Repeat("1234567890", 3)+"12345678"] = strings.Repeat("1234567890", 3) + "12345678"
+	testCases["1E-130"] = "0." + strings.Repeat("0", 129) + "1"
+	testCases["9."+strings.Repeat("9", 37)+"E+125"] = strings.Repeat("9", 38) + strings.Repeat("0", 88)
+	for in, want := range testCases {
+		in, want := in, want
+		t.Run(in, func(t *testing.T) {
+			n, err := ParseNumber(in)
+			require.NoError(t, err)
+			require.Equal(t, want, n.String())
+		})
+	}
+}
+
+func TestParseNumberErr(t *testing.T) {
+	testCases := []string{
+		"",
+		"-",
+		".",
+		"abc",
+		"1..2",
+		"1e",
+		" 1",
+		"0x10",
+		"123456789012345678901234567890123456789",
+		"1E-131",
+		"1E126",
+		"-1E126",
+		"1E99999999999999999999",
+	}
+	for _, in := range testCases {
+		_, err := ParseNumber(in)
+		requireErrIs(t, err, ErrInvalidNumber)
+	}
+}
+
+func TestNumberCmp(t *testing.T) {
+	testCases := []struct {
+		n1, n2 string
+		want   int
+	}{
+		{"1", "1.0", 0},
+		{"0", "-0", 0},
+		{"1", "2", -1},
+		{"2", "10", -1},
+		{"-2", "-10", 1},
+		{"-1", "0", -1},
+		{"0", "0.001", -1},
+		{"0.2", "0.123", 1},
+		{"12345678901234567890123456789012345678", "12345678901234567890123456789012345679", -1},
+		{"1E125", "9E124", 1},
+		{"-1E-130", "1E-130", -1},
+	}
+	for _, tc := range testCases {
+		n1, err := ParseNumber(tc.n1)
+		require.NoError(t, err)
+		n2, err := ParseNumber(tc.n2)
+		require.NoError(t, err)
+		require.Equalf(t, tc.want, n1.Cmp(n2), "%s cmp %s", tc.n1, tc.n2)
+		require.Equalf(t, -tc.want, n2.Cmp(n1), "%s cmp %s", tc.n2, tc.n1)
+	}
+}
+
+func TestCmpNumberStrings(t *testing.T) {
+	_, ok := cmpNumberStrings("x", "1")
+	require.False(t, ok)
+	_, ok = cmpNumberStrings("1", "x")
+	require.False(t, ok)
+	c, ok := cmpNumberStrings("1.50", "1.5")
+	require.True(t, ok)
+	require.Equal(t, 0, c)
+}
+
+func TestNumberAdd(t *testing.T) {
+	testCases := map[string]struct {
+		a, b, want string
+	}{
+		"int":      {a: "1", b: "2", want: "3"},
+		"decimal":  {a: "0.1", b: "0.2", want: "0.3"},
+		"negative": {a: "-1.5", b: "0.25", want: "-1.25"},
+		"zero":     {a: "0", b: "7E3", want: "7000"},
+		"cancel":   {a: "12.5", b: "-12.5", want: "0"},
+		"exact":    {a: "99999999999999999999999999999999999999", b: "-1", want: "99999999999999999999999999999999999998"},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			a, err := ParseNumber(tc.a)
+			require.NoError(t, err)
+			b, err := ParseNumber(tc.b)
+			require.NoError(t, err)
+			got, err := a.Add(b)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestNumberAddErr(t *testing.T) {
+	a, err := ParseNumber("99999999999999999999999999999999999999")
+	require.NoError(t, err)
+	b, err := ParseNumber("0.1")
+	require.NoError(t, err)
+	_, err = a.Add(b)
+	requireErrIs(t, err, ErrInvalidNumber)
+}
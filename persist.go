@@ -0,0 +1,322 @@
+package dynamock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"foxygo.at/s/errs"
+)
+
+var ErrPersist = errors.New("cannot persist")
+
+// persister writes a DB to its fixture file or directory after
+// mutations, see DB.Persist.
+type persister struct {
+	m       sync.Mutex
+	path    string
+	delay   time.Duration
+	timer   Timer
+	pending bool
+	err     error
+
+	// log is the write-ahead log of OpenDBWithLog, see wal.go.
+	log          *os.File
+	records      int
+	compactEvery int
+}
+
+// OpenDB reads the fixture file or table directory layout at path, see
+// NewDBFromFile and NewDBFromDir, and persists every successful mutation
+// back to it, see Persist.
+func OpenDB(path string) (*DB, error) {
+	db, err := newDBFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	_ = db.Persist(path, 0) // there is nothing to flush yet
+	return db, nil
+}
+
+func newDBFromPath(path string) (*DB, error) {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return NewDBFromDir(path)
+	}
+	return NewDBFromFile(path)
+}
+
+// Persist writes db to path after every successful PutItem, DeleteItem
+// and UpdateItem. If path is an existing directory, every table is
+// written to the table directory it was read from, see NewDBFromDir, or
+// else to a subdirectory named after the table. Items are written back to
+// the table file or item file they were read from and new items to the
+// table file; no files are removed. Persisting fails if a table directory
+// holds item files the table was not read from. Otherwise path is
+// written as a single fixture file, in YAML for the file extensions .yaml
+// and .yml and in JSON otherwise.
+//
+// Files are written to a temporary file that is then renamed, so the
+// fixture is never left partially written. If delay is 0, every
+// mutation is written before it returns and write errors are returned
+// by the mutation. Otherwise writes are debounced: db is written once no
+// mutation has happened for delay, e.g. after a bulk load, and write
+// errors are returned by the next Flush.
+//
+// Mutations are applied to db before they are written. A mutation whose
+// write fails is not undone: it returns an error wrapping ErrPersist and
+// path lags behind db until the next successful write. A failed write is
+// retried by Flush.
+//
+// Persist flushes pending writes of a previous Persist call and closes
+// its write-ahead log, see Close.
+func (db *DB) Persist(path string, delay time.Duration) error {
+	err := db.Close()
+	db.m.Lock()
+	db.persister = &persister{path: path, delay: delay}
+	db.m.Unlock()
+	return err
+}
+
+// getPersister returns the persister of db, or nil if db is not
+// persisted.
+func (db *DB) getPersister() *persister {
+	db.m.RLock()
+	defer db.m.RUnlock()
+	return db.persister
+}
+
+// Flush writes pending debounced mutations and returns the first error
+// of debounced writes since the last Flush.
+func (db *DB) Flush() error {
+	p := db.getPersister()
+	if p == nil {
+		return nil
+	}
+	p.m.Lock()
+	defer p.m.Unlock()
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	err := p.err
+	p.err = nil
+	if p.pending {
+		werr := db.writeTo(p.path)
+		p.pending = werr != nil
+		if err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// Close flushes pending writes, closes the write-ahead log and stops
+// persisting mutations.
+func (db *DB) Close() error {
+	err := db.Flush()
+	db.m.Lock()
+	p := db.persister
+	db.persister = nil
+	db.m.Unlock()
+	if p != nil && p.log != nil {
+		if cerr := p.log.Close(); err == nil && cerr != nil {
+			err = errs.Errorf("%v: %v", ErrPersist, cerr)
+		}
+	}
+	return err
+}
+
+// changed persists db after a successful mutation of the item with the
+// primary key of key in table. The mutation is kept if persisting fails.
+func (db *DB) changed(table *Table, key Item) error {
+	p := db.getPersister()
+	if p == nil {
+		return nil
+	}
+	p.m.Lock()
+	defer p.m.Unlock()
+	if p.log != nil {
+		return db.appendLog(p, table, key)
+	}
+	if p.delay == 0 {
+		err := db.writeTo(p.path)
+		p.pending = err != nil
+		return err
+	}
+	p.pending = true
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.timer = db.Clock().AfterFunc(p.delay, func() { db.flushDebounced(p) })
+	return nil
+}
+
+// flushDebounced writes pending mutations when the debounce timer fires.
+// Write errors are kept for Flush, which retries the write.
+func (db *DB) flushDebounced(p *persister) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if !p.pending {
+		return
+	}
+	err := db.writeTo(p.path)
+	p.pending = err != nil
+	if err != nil && p.err == nil {
+		p.err = err
+	}
+}
+
+func (db *DB) writeTo(path string) error {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return db.writeDir(path)
+	}
+	buf := &bytes.Buffer{}
+	if isYAMLFile(path) {
+		_ = db.WriteYAMLSnap(buf)
+	} else {
+		_ = db.WriteSnap(buf)
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+func (db *DB) writeDir(dir string) error {
+	for _, t := range db.tableList() {
+		if err := t.writeDir(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDir writes t to its table directory in dir. Items are written back
+// to the table file or item file they were read from, see NewDBFromDir,
+// new items to the table file. Other fixture files in the table directory
+// would duplicate items on the next load and are an error.
+func (t *Table) writeDir(dir string) error {
+	src := t.source
+	if src == nil {
+		src = &dirSource{dir: t.name}
+	}
+	tableDir := filepath.Join(dir, src.dir)
+	if err := os.MkdirAll(tableDir, 0o755); err != nil {
+		return errs.Errorf("%v: %v", ErrPersist, err)
+	}
+	tableFile := src.tableFile
+	if tableFile == "" {
+		tableFile = tableFiles[0]
+		for _, tf := range tableFiles {
+			if _, err := os.Stat(filepath.Join(tableDir, tf)); err == nil {
+				tableFile = tf
+				break
+			}
+		}
+	}
+	known := map[string]bool{tableFile: true}
+	for _, fname := range src.itemFiles {
+		known[fname] = true
+	}
+	if t.createTable != "" && !filepath.IsAbs(t.createTable) {
+		known[filepath.Clean(t.createTable)] = true
+	}
+	fis, err := ioutil.ReadDir(tableDir)
+	if err != nil {
+		return errs.Errorf("%v: %v", ErrPersist, err)
+	}
+	for _, fi := range fis {
+		if !fi.IsDir() && isFixtureFile(fi.Name()) && !known[fi.Name()] {
+			return errs.Errorf("%v: unknown item file '%s'", ErrPersist, filepath.Join(tableDir, fi.Name()))
+		}
+	}
+
+	t.m.RLock()
+	jt := t.snapLocked("")
+	files := map[string][]map[string]interface{}{}
+	for i, item := range t.items {
+		k, _ := getKeyStrings(item, t.schema.PrimaryKey)
+		fname := src.files[*k]
+		if fname == "" {
+			fname = tableFile
+		}
+		files[fname] = append(files[fname], jt.Items[i])
+	}
+	t.m.RUnlock()
+
+	jt.Items = files[tableFile]
+	if jt.Items == nil {
+		jt.Items = []map[string]interface{}{}
+	}
+	var b []byte
+	if isYAMLFile(tableFile) {
+		b = encodeYAML(jt)
+	} else {
+		b, _ = json.MarshalIndent(jt, "", "  ")
+	}
+	if err := writeFileAtomic(filepath.Join(tableDir, tableFile), b); err != nil {
+		return err
+	}
+	for _, fname := range src.itemFiles {
+		if err := writeFileAtomic(filepath.Join(tableDir, fname), encodeItemFile(fname, files[fname])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeItemFile encodes items as item file fname, see NewDBFromDir.
+func encodeItemFile(fname string, items []map[string]interface{}) []byte {
+	if items == nil {
+		items = []map[string]interface{}{}
+	}
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".jsonl":
+		buf := &bytes.Buffer{}
+		for _, item := range items {
+			b, _ := json.Marshal(item)
+			buf.Write(append(b, '\n'))
+		}
+		return buf.Bytes()
+	case ".yaml", ".yml":
+		return encodeYAML(items)
+	}
+	b, _ := json.MarshalIndent(items, "", "  ")
+	return b
+}
+
+// writeFileAtomic writes b to a temporary file next to fname and renames
+// it to fname, keeping the permissions of an existing fname.
+func writeFileAtomic(fname string, b []byte) error {
+	mode := os.FileMode(0o600)
+	if fi, err := os.Stat(fname); err == nil {
+		mode = fi.Mode()
+	}
+	tmp := filepath.Join(filepath.Dir(fname), "."+filepath.Base(fname)+".tmp")
+	if err := ioutil.WriteFile(tmp, b, mode); err != nil {
+		return errs.Errorf("%v: %v", ErrPersist, err)
+	}
+	if err := os.Rename(tmp, fname); err != nil {
+		_ = os.Remove(tmp)
+		return errs.Errorf("%v: %v", ErrPersist, err)
+	}
+	return nil
+}
+
+func isYAMLFile(fname string) bool {
+	ext := strings.ToLower(filepath.Ext(fname))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// isFixtureFile reports whether NewDBFromDir reads fname as table or item
+// file.
+func isFixtureFile(fname string) bool {
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".json", ".jsonl", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+package dynamock
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func copyTestdata(t *testing.T, files ...string) string {
+	t.Helper()
+	contents := map[string]string{}
+	for _, fname := range files {
+		contents[fname] = string(ReadTestdataBytes(t, fname))
+	}
+	return writeExportFiles(t, contents)
+}
+
+func putProduct(t *testing.T, db *DB, id string) error {
+	t.Helper()
+	_, err := db.PutItem(&dynamodb.PutItemInput{
+		TableName: strPtr("product"),
+		Item:      Item{"id": {S: strPtr(id)}, "name": {S: strPtr("pen " + id)}},
+	})
+	return err
+}
+
+func requireProduct(t *testing.T, fname, id string, want bool) {
+	t.Helper()
+	db, err := OpenDB(fname)
+	require.NoError(t, err)
+	item, err := db.tables["product"].Get(Item{"id": {S: strPtr(id)}})
+	require.NoError(t, err)
+	require.Equal(t, want, item != nil)
+}
+
+func TestOpenDBFile(t *testing.T) {
+	for _, fname := range []string{"db.json", "db.yaml"} {
+		fname := fname
+		t.Run(fname, func(t *testing.T) {
+			dir := copyTestdata(t, fname)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, fname)
+			db, err := OpenDB(path)
+			require.NoError(t, err)
+
+			require.NoError(t, putProduct(t, db, "9"))
+			requireProduct(t, path, "9", true)
+
+			_, err = db.UpdateItem(&dynamodb.UpdateItemInput{
+				TableName:                 strPtr("product"),
+				Key:                       Item{"id": {S: strPtr("9")}},
+				UpdateExpression:          strPtr("SET price = :p"),
+				ExpressionAttributeValues: Item{":p": {N: strPtr("99")}},
+			})
+			require.NoError(t, err)
+			db2, err := NewDBFromFile(path)
+			require.NoError(t, err)
+			require.Equal(t, "99", *db2.tables["product"].byPrimary["9"][""]["price"].N)
+
+			_, err = db.DeleteItem(&dynamodb.DeleteItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr("9")}}})
+			require.NoError(t, err)
+			requireProduct(t, path, "9", false)
+
+			b, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, isYAMLFile(fname), strings.HasPrefix(string(b), "tables:"))
+			fis, err := ioutil.ReadDir(dir)
+			require.NoError(t, err)
+			require.Len(t, fis, 1)
+		})
+	}
+}
+
+func TestOpenDBDir(t *testing.T) {
+	dir := writeExportFiles(t, map[string]string{
+		"product/table.json": `{"schema": {"primaryKey": {"partitionKey": {"name": "id", "type": "string"}}}}`,
+		"product/a.json":     `[{"id": "1"}]`,
+		"product/b.jsonl":    `{"id": "2"}`,
+		"product/README.md":  "kept",
+		"person/table.yaml":  "schema: {primaryKey: {partitionKey: {name: id, type: number}}}\nitems: [{id: 1}]",
+	})
+	defer os.RemoveAll(dir)
+	db, err := OpenDB(dir)
+	require.NoError(t, err)
+	require.NoError(t, putProduct(t, db, "3"))
+	_, err = db.DeleteItem(&dynamodb.DeleteItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr("2")}}})
+	require.NoError(t, err)
+	require.NoError(t, putProduct(t, db, "2"))
+
+	// items are written back to the files they were read from
+	fis, err := ioutil.ReadDir(filepath.Join(dir, "product"))
+	require.NoError(t, err)
+	names := []string{}
+	for _, fi := range fis {
+		names = append(names, fi.Name())
+	}
+	require.Equal(t, []string{"README.md", "a.json", "b.jsonl", "table.json"}, names)
+	b, err := ioutil.ReadFile(filepath.Join(dir, "product", "b.jsonl"))
+	require.NoError(t, err)
+	require.Equal(t, `{"id":"2","name":"pen 2"}`+"\n", string(b))
+	b, err = ioutil.ReadFile(filepath.Join(dir, "product", "table.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"pen 3"`)
+	require.NotContains(t, string(b), `"1"`)
+	b, err = ioutil.ReadFile(filepath.Join(dir, "person", "table.yaml"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(b), "name: person\n"))
+
+	db2, err := NewDBFromDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"person", "product"}, db2.tableNames)
+	require.Len(t, db2.tables["product"].items, 3)
+	require.Len(t, db2.tables["person"].items, 1)
+
+	// tables missing from the directory are added
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "person")))
+	require.NoError(t, putProduct(t, db, "4"))
+	db2, err = NewDBFromDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"person", "product"}, db2.tableNames)
+}
+
+func TestOpenDBDirSource(t *testing.T) {
+	create := `{"TableName": "order", "KeySchema": [{"AttributeName": "id", "KeyType": "HASH"}],
+		"AttributeDefinitions": [{"AttributeName": "id", "AttributeType": "S"}]}`
+	dir := writeExportFiles(t, map[string]string{
+		"t/table.json":  `{"createTable": "create.json", "items": [{"id": "1"}]}`,
+		"t/create.json": create,
+		"t/more.yml":    "- {id: '2', tags: [a]}\n",
+		"r/table.yml":   "name: renamed\nschema: {primaryKey: {partitionKey: {name: id, type: string}}}\nsets: {tags: string}\n",
+		"r/a.yaml":      "- {id: '1', tags: [a, b]}\n",
+	})
+	defer os.RemoveAll(dir)
+	db, err := OpenDB(dir)
+	require.NoError(t, err)
+	for _, table := range []string{"order", "renamed"} {
+		_, err = db.PutItem(&dynamodb.PutItemInput{TableName: strPtr(table), Item: Item{"id": {S: strPtr("3")}}})
+		require.NoError(t, err)
+		_, err = db.DeleteItem(&dynamodb.DeleteItemInput{TableName: strPtr(table), Key: Item{"id": {S: strPtr("1")}}})
+		require.NoError(t, err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "t", "create.json"))
+	require.NoError(t, err)
+	require.Equal(t, create, string(b))
+	b, err = ioutil.ReadFile(filepath.Join(dir, "r", "a.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "[]\n", string(b))
+	fis, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, fis, 2)
+
+	db2, err := NewDBFromDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"renamed", "order"}, db2.tableNames)
+	for _, table := range []string{"order", "renamed"} {
+		got := db2.tables[table]
+		require.ElementsMatch(t, db.tables[table].items, got.items)
+		require.Equal(t, db.tables[table].source.dir, got.source.dir)
+		require.Equal(t, db.tables[table].source.itemFiles, got.source.itemFiles)
+	}
+	require.Equal(t, "create.json", db2.tables["order"].createTable)
+	require.Len(t, db2.tables["order"].items, 2)
+	require.NotNil(t, db2.tables["order"].byPrimary["2"][""]["tags"].L)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "t", "more.yml")))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "t", "more.yml"), 0o755))
+	_, err = db.PutItem(&dynamodb.PutItemInput{TableName: strPtr("order"), Item: Item{"id": {S: strPtr("4")}}})
+	requireErrIs(t, err, ErrPersist)
+}
+
+func TestOpenDBErr(t *testing.T) {
+	_, err := OpenDB(filepath.Join("testdata", "MISSING.json"))
+	require.Error(t, err)
+	_, err = OpenDB(filepath.Join("testdata", "fixturedir"))
+	require.Error(t, err)
+}
+
+func TestPersistDebounce(t *testing.T) {
+	dir := copyTestdata(t, "db.json")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.json")
+	db, err := NewDBFromFile(path)
+	require.NoError(t, err)
+	require.NoError(t, db.Persist(path, time.Hour))
+
+	require.NoError(t, putProduct(t, db, "8"))
+	require.NoError(t, putProduct(t, db, "9"))
+	requireProduct(t, path, "9", false)
+	require.NoError(t, db.Flush())
+	requireProduct(t, path, "8", true)
+	requireProduct(t, path, "9", true)
+	require.NoError(t, db.Flush())
+
+	require.NoError(t, db.Persist(path, time.Millisecond))
+	require.NoError(t, putProduct(t, db, "10"))
+	require.Eventually(t, func() bool {
+		db2, err := NewDBFromFile(path)
+		return err == nil && db2.tables["product"].byPrimary["10"] != nil
+	}, time.Second, time.Millisecond)
+
+	// a timer firing after Flush has nothing to write
+	db.flushDebounced(db.persister)
+	require.NoError(t, db.Flush())
+	require.NoError(t, NewDB().Flush())
+}
+
+func TestPersistDebounceClock(t *testing.T) {
+	dir := copyTestdata(t, "db.json")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.json")
+	db, err := NewDBFromFile(path)
+	require.NoError(t, err)
+	clock := NewManualClock(epoch)
+	db.SetClock(clock)
+	require.NoError(t, db.Persist(path, time.Second))
+
+	require.NoError(t, putProduct(t, db, "8"))
+	clock.Advance(999 * time.Millisecond)
+	require.NoError(t, putProduct(t, db, "9"))
+	clock.Advance(999 * time.Millisecond)
+	requireProduct(t, path, "8", false)
+	clock.Advance(time.Millisecond)
+	requireProduct(t, path, "8", true)
+	requireProduct(t, path, "9", true)
+}
+
+func TestPersistErr(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	missing := filepath.Join("testdata", "MISSING", "db.json")
+	require.NoError(t, db.Persist(missing, 0))
+	err := putProduct(t, db, "9")
+	requireErrIs(t, err, ErrPersist)
+	// the mutation is kept
+	item, err := db.Table("product").Get(Item{"id": {S: strPtr("9")}})
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	_, err = db.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 strPtr("product"),
+		Key:                       Item{"id": {S: strPtr("9")}},
+		UpdateExpression:          strPtr("SET price = :p"),
+		ExpressionAttributeValues: Item{":p": {N: strPtr("99")}},
+	})
+	requireErrIs(t, err, ErrPersist)
+	_, err = db.DeleteItem(&dynamodb.DeleteItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr("9")}}})
+	requireErrIs(t, err, ErrPersist)
+
+	// failed writes are retried by Flush
+	requireErrIs(t, db.Flush(), ErrPersist)
+	requireErrIs(t, db.Persist(missing, time.Hour), ErrPersist)
+	require.NoError(t, putProduct(t, db, "10"))
+	err = db.Persist(missing, time.Millisecond)
+	requireErrIs(t, err, ErrPersist)
+
+	// debounced write errors are returned by the next Flush
+	require.NoError(t, putProduct(t, db, "11"))
+	require.Eventually(t, func() bool {
+		db.persister.m.Lock()
+		defer db.persister.m.Unlock()
+		return db.persister.err != nil
+	}, time.Second, time.Millisecond)
+	require.NoError(t, putProduct(t, db, "12"))
+	db.persister.timer.Stop()
+	err = db.Flush()
+	requireErrIs(t, err, ErrPersist)
+	requireErrIs(t, db.Flush(), ErrPersist)
+	requireErrIs(t, db.Close(), ErrPersist)
+	require.NoError(t, db.Flush())
+}
+
+func TestPersistConcurrent(t *testing.T) {
+	dir := copyTestdata(t, "db.json")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.json")
+	db, err := OpenDB(path)
+	require.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			_ = db.Persist(path, 0)
+			_ = db.Close()
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, putProduct(t, db, strconv.Itoa(i)))
+	}
+	<-done
+}
+
+func TestPersistDirErr(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	testCases := map[string]map[string]string{
+		"table_dir_is_file": {"product": "not a directory"},
+		"table_file_is_dir": {"product/table.json/x": "not a file"},
+		"item_dir_kept":     {"product/a.json/x": "directories are not item files"},
+		"unknown_item_file": {"product/a.json": "[]"},
+	}
+	for name, files := range testCases {
+		files := files
+		t.Run(name, func(t *testing.T) {
+			dir := writeExportFiles(t, files)
+			defer os.RemoveAll(dir)
+			require.NoError(t, db.Persist(dir, 0))
+			err := putProduct(t, db, name)
+			if name == "item_dir_kept" {
+				require.NoError(t, err)
+				return
+			}
+			requireErrIs(t, err, ErrPersist)
+		})
+	}
+}
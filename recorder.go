@@ -0,0 +1,118 @@
+package dynamock
+
+import (
+	"sync"
+	"time"
+)
+
+// Call is a dynamodbiface.DynamoDBAPI call recorded by a Recorder.
+type Call struct {
+	// Op is the DynamoDB operation, e.g. PutItem for the methods PutItem,
+	// PutItemWithContext and PutItemRequest.
+	Op string
+	// Table is the TableName of Input, if any.
+	Table string
+	// Input is the input of the call, e.g. a *dynamodb.PutItemInput.
+	Input interface{}
+	// Output is the output of the call, e.g. a *dynamodb.PutItemOutput,
+	// or nil if the call failed or the operation is not implemented.
+	Output   interface{}
+	Err      error
+	Duration time.Duration
+}
+
+// String returns the operation and table of c, e.g. "PutItem product".
+func (c Call) String() string {
+	if c.Table == "" {
+		return c.Op
+	}
+	return c.Op + " " + c.Table
+}
+
+// Recorder records the calls of a DB in order, see DB.Record. It is safe
+// for concurrent use.
+type Recorder struct {
+	m     sync.Mutex
+	calls []Call
+}
+
+// Record starts recording every dynamodbiface.DynamoDBAPI call of db,
+// including calls of unimplemented methods, and returns the Recorder. A
+// further Record call replaces the Recorder. Record must not be called
+// concurrently with calls of db.
+func (db *DB) Record() *Recorder {
+	db.recorder = &Recorder{}
+	return db.recorder
+}
+
+// StopRecording stops recording the calls of db, see Record.
+func (db *DB) StopRecording() {
+	db.recorder = nil
+}
+
+// Calls returns the recorded calls in order.
+func (r *Recorder) Calls() []Call {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Filter returns the recorded calls of the operation op on table in
+// order. Empty op or table match all operations or tables.
+func (r *Recorder) Filter(op, table string) []Call {
+	var calls []Call
+	for _, c := range r.Calls() {
+		if (op == "" || c.Op == op) && (table == "" || c.Table == table) {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Count returns the number of recorded calls of the operation op on
+// table, see Filter.
+func (r *Recorder) Count(op, table string) int {
+	return len(r.Filter(op, table))
+}
+
+// Strings returns the recorded calls in order formatted with
+// Call.String, e.g. for comparing them to an expected call sequence.
+func (r *Recorder) Strings() []string {
+	calls := r.Calls()
+	strs := make([]string, len(calls))
+	for i, c := range calls {
+		strs[i] = c.String()
+	}
+	return strs
+}
+
+// Reset discards all recorded calls.
+func (r *Recorder) Reset() {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.calls = nil
+}
+
+// record records a call if recording is enabled and returns err.
+func (u *UnimplementedDB) record(op string, in, out interface{}, err error, d time.Duration) error {
+	if u == nil || u.recorder == nil {
+		return err
+	}
+	if err != nil {
+		out = nil
+	}
+	c := Call{Op: op, Table: tableName(in), Input: in, Output: out, Err: err, Duration: d}
+	u.recorder.m.Lock()
+	defer u.recorder.m.Unlock()
+	u.recorder.calls = append(u.recorder.calls, c)
+	return err
+}
+
+// tableName returns the TableName field of the input in or "".
+func tableName(in interface{}) string {
+	f := inputField(in, "TableName")
+	if !f.IsValid() || f.IsNil() {
+		return ""
+	}
+	return f.Elem().String()
+}
@@ -0,0 +1,89 @@
+package dynamock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	_, err := db.GetItem(&dynamodb.GetItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr("1")}}})
+	require.NoError(t, err)
+
+	r := db.Record()
+	ctx := context.Background()
+	getIn := &dynamodb.GetItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr("1")}}}
+	getOut, err := db.GetItemWithContext(ctx, getIn)
+	require.NoError(t, err)
+	putIn := &dynamodb.PutItemInput{
+		TableName:           strPtr("product"),
+		Item:                Item{"id": {S: strPtr("1")}},
+		ConditionExpression: strPtr("attribute_not_exists(id)"),
+	}
+	_, err = db.PutItemWithContext(ctx, putIn)
+	requireErrIs(t, err, ErrConditionalCheckFailed)
+	_, err = db.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 strPtr("product"),
+		Key:                       Item{"id": {S: strPtr("1")}},
+		UpdateExpression:          strPtr("SET price = :p"),
+		ExpressionAttributeValues: Item{":p": {N: strPtr("12")}},
+	})
+	require.NoError(t, err)
+	_, err = db.QueryWithContext(ctx, &dynamodb.QueryInput{
+		TableName:                 strPtr("person"),
+		KeyConditionExpression:    strPtr("id = :id"),
+		ExpressionAttributeValues: Item{":id": {N: strPtr("1")}},
+	})
+	require.NoError(t, err)
+	_, err = db.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr("2")}}})
+	require.NoError(t, err)
+	_, err = db.ScanWithContext(ctx, &dynamodb.ScanInput{TableName: strPtr("product")})
+	requireErrIs(t, err, ErrUnimpl)
+	req, _ := db.ScanRequest(&dynamodb.ScanInput{TableName: strPtr("person")})
+	require.Nil(t, req)
+	_, err = db.BatchWriteItem(&dynamodb.BatchWriteItemInput{})
+	requireErrIs(t, err, ErrUnimpl)
+	_, err = db.GetItem(nil)
+	requireErrIs(t, err, ErrNil)
+
+	want := []string{
+		"GetItem product",
+		"PutItem product",
+		"UpdateItem product",
+		"Query person",
+		"DeleteItem product",
+		"Scan product",
+		"Scan person",
+		"BatchWriteItem",
+		"GetItem",
+	}
+	require.Equal(t, want, r.Strings())
+	calls := r.Calls()
+	require.Equal(t, Call{Op: "GetItem", Table: "product", Input: getIn, Output: getOut, Duration: calls[0].Duration}, calls[0])
+	require.Equal(t, putIn, calls[1].Input)
+	require.Nil(t, calls[1].Output)
+	requireErrIs(t, calls[1].Err, ErrConditionalCheckFailed)
+	require.NoError(t, calls[6].Err)
+
+	require.Equal(t, 5, r.Count("", "product"))
+	require.Equal(t, 2, r.Count("Scan", ""))
+	require.Equal(t, 1, r.Count("Scan", "person"))
+	require.Empty(t, r.Filter("Query", "product"))
+	require.Len(t, r.Filter("", ""), len(want))
+
+	r.Reset()
+	require.Empty(t, r.Calls())
+	db.StopRecording()
+	_, _ = db.Scan(nil)
+	require.Empty(t, r.Calls())
+	require.NotSame(t, r, db.Record())
+}
+
+func TestRecordNilUnimplementedDB(t *testing.T) {
+	var u *UnimplementedDB
+	_, err := u.Scan(nil)
+	requireErrIs(t, err, ErrUnimpl)
+}
@@ -24,6 +24,14 @@ type Table struct {
 	m      sync.RWMutex
 	name   string
 	schema Schema
+	format string // fixture format the table was loaded from
+	// createTable is the CreateTable JSON file the schema was loaded from.
+	createTable string
+	// clock is the Clock of the DB of the table, see DB.SetClock.
+	clock Clock
+	// source is the table directory the table was read from, see
+	// NewDBFromDir. It is not modified after loading.
+	source *dirSource
 
 	items []Item
 	// byPrimary is a lookup of Primary key by partition and sort key - unique result required.
@@ -32,6 +40,9 @@ type Table struct {
 	// indexName is either Global Secondary Index name or "/" for primaryKey.
 	// the resulting slice of Items is sorted by sortKey
 	byIndex map[string]map[string][]Item
+	// shared is set if items, byPrimary and byIndex are shared with a
+	// checkpoint and must be copied before mutation, see own.
+	shared bool
 }
 
 type Item = map[string]*dynamodb.AttributeValue
@@ -46,7 +57,21 @@ func ItemToJSON(i Item) string {
 type Schema struct {
 	PrimaryKey KeyDef   `json:"primaryKey"`
 	GSIs       []KeyDef `json:"globalSecondaryIndex,omitempty"`
-	gsis       map[string]KeyDef
+	// LSIs share the partition key of the PrimaryKey and are queried like GSIs.
+	LSIs []KeyDef `json:"localSecondaryIndex,omitempty"`
+	// TTLAttribute is the time to live attribute name if TTL is enabled.
+	// It is metadata only: DB does not expire items.
+	TTLAttribute string `json:"ttlAttribute,omitempty"`
+	// StreamViewType is KEYS_ONLY, NEW_IMAGE, OLD_IMAGE or
+	// NEW_AND_OLD_IMAGES if streams are enabled. It is metadata only: DB
+	// has no streams.
+	StreamViewType string `json:"streamViewType,omitempty"`
+	gsis           map[string]KeyDef
+}
+
+// secondaryIndexes returns the global and local secondary indexes of s.
+func (s Schema) secondaryIndexes() []KeyDef {
+	return append(append([]KeyDef{}, s.GSIs...), s.LSIs...)
 }
 
 type KeyDef struct {
@@ -57,7 +82,12 @@ type KeyDef struct {
 
 type KeyPartDef struct {
 	Name string `json:"name"`
-	Type string `json:"type"` // string, number;  binary  not implemented
+	Type string `json:"type"` // string, number, binary
+}
+
+// Schema returns the schema of t.
+func (t *Table) Schema() Schema {
+	return t.schema
 }
 
 func (t *Table) WriteSnap(w io.Writer, cols []string) error {
@@ -75,30 +105,38 @@ func SnapString(items []Item, cols []string) string {
 func WriteSnap(w io.Writer, items []Item, cols []string) error {
 	iitems := []map[string]interface{}{}
 	_ = dynamodbattribute.UnmarshalListOfMaps(items, &iitems)
-	format := rowFormat(cols, iitems)
-	row := make([]interface{}, len(cols))
+	rows := make([][]interface{}, len(iitems))
+	for r, iitem := range iitems {
+		rows[r] = make([]interface{}, len(cols))
+		for i, col := range cols {
+			rows[r][i] = iitem[col]
+		}
+	}
+	writeRows(w, cols, rows)
+	return nil
+}
+
+// writeRows writes rows as right aligned, comma separated columns below
+// a header row of cols.
+func writeRows(w io.Writer, cols []string, rows [][]interface{}) {
+	format := rowFormat(cols, rows)
 	untypedCols := make([]interface{}, len(cols))
 	for i, c := range cols {
 		untypedCols[i] = c
 	}
 	fmt.Fprintf(w, format, untypedCols...)
-	for _, iitem := range iitems {
-		for i, col := range cols {
-			row[i] = iitem[col]
-		}
+	for _, row := range rows {
 		fmt.Fprintf(w, format, row...)
 	}
-	return nil
 }
 
-func rowFormat(cols []string, iitems []map[string]interface{}) string {
+func rowFormat(cols []string, rows [][]interface{}) string {
 	pads := make([]int, len(cols))
 	for i, c := range cols {
 		pads[i] = len(c)
 	}
-	for _, item := range iitems {
-		for i, c := range cols {
-			attr := item[c]
+	for _, row := range rows {
+		for i, attr := range row {
 			l := len(fmt.Sprint(attr))
 			if l > pads[i] {
 				pads[i] = l
@@ -115,7 +153,7 @@ func rowFormat(cols []string, iitems []map[string]interface{}) string {
 // derived from items, must be set
 func (t *Table) index() error {
 	t.schema.gsis = map[string]KeyDef{}
-	for _, gsi := range t.schema.GSIs {
+	for _, gsi := range t.schema.secondaryIndexes() {
 		t.schema.gsis[gsi.Name] = gsi
 	}
 	pk := t.schema.PrimaryKey
@@ -128,21 +166,23 @@ func (t *Table) index() error {
 		t.byIndex[name] = map[string][]Item{}
 	}
 	for _, item := range t.items {
-		if err := t.indexItem(item); err != nil {
+		if err := t.indexItemByPrimaryKey(item); err != nil {
 			return err
 		}
 	}
+	for _, gsi := range t.schema.gsis {
+		t.indexItemsByKey(gsi)
+	}
 	return nil
 }
 
-func (t *Table) indexItem(item Item) error {
-	if err := t.indexItemByPrimaryKey(item); err != nil {
-		return err
-	}
+// indexItem adds item, whose primary key must not be indexed yet, to all
+// indexes.
+func (t *Table) indexItem(item Item) {
+	_ = t.indexItemByPrimaryKey(item)
 	for _, gsi := range t.schema.gsis {
 		t.indexItemByKey(item, gsi)
 	}
-	return nil
 }
 
 func (t *Table) indexItemByPrimaryKey(item Item) error {
@@ -166,12 +206,76 @@ func (t *Table) indexItemByKey(item Item, gsi KeyDef) {
 	t.byIndex[gsi.Name][k.PartitionKey] = insertItem(items, item, gsi.SortKey)
 }
 
+// indexItemsByKey indexes all items like indexItemByKey, but sorts every
+// partition once rather than inserting items one by one, so that large
+// tables index quickly. As with indexItemByKey, items with equal sort
+// keys are in reverse order of t.items.
+func (t *Table) indexItemsByKey(gsi KeyDef) {
+	byPartition := t.byIndex[gsi.Name]
+	for _, item := range t.items {
+		if hasKey(item, gsi) {
+			k, _ := getKeyStrings(item, gsi)
+			byPartition[k.PartitionKey] = append(byPartition[k.PartitionKey], item)
+		}
+	}
+	if gsi.SortKey == nil {
+		return
+	}
+	for _, items := range byPartition {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+		sortItems(items, gsi.SortKey)
+	}
+}
+
+// sortItems stable sorts items by sortKey.
+func sortItems(items []Item, sortKey *KeyPartDef) {
+	cmp := sortKeyCmp(items, sortKey)
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return cmp(idx[a], idx[b]) < 0 })
+	sorted := make([]Item, len(items))
+	for i, j := range idx {
+		sorted[i] = items[j]
+	}
+	copy(items, sorted)
+}
+
+// sortKeyCmp returns a function comparing items[i] and items[j] by
+// sortKey. Numbers are parsed once up front.
+func sortKeyCmp(items []Item, sortKey *KeyPartDef) func(i, j int) int {
+	name := sortKey.Name
+	switch sortKey.Type {
+	case "string":
+		return func(i, j int) int { return strings.Compare(*items[i][name].S, *items[j][name].S) }
+	case "binary":
+		return func(i, j int) int { return bytes.Compare(items[i][name].B, items[j][name].B) }
+	}
+	nums := make([]Number, len(items))
+	for i, item := range items {
+		nums[i], _ = ParseNumber(*item[name].N)
+	}
+	return func(i, j int) int { return nums[i].Cmp(nums[j]) }
+}
+
 func (t *Table) Delete(key Item) (Item, error) {
+	return t.delete(key, nil)
+}
+
+func (t *Table) delete(key Item, cond condition) (Item, error) {
 	t.m.Lock()
 	defer t.m.Unlock()
 	if err := validateKeyItem(key, t.schema); err != nil {
 		return nil, err
 	}
+	k, _ := getKeyStrings(key, t.schema.PrimaryKey)
+	if err := checkCondition(cond, t.get(k)); err != nil {
+		return nil, err
+	}
+	t.own()
 	return t.pop(key), nil
 }
 
@@ -186,14 +290,23 @@ func (t *Table) Get(key Item) (Item, error) {
 }
 
 func (t *Table) Put(item Item) (Item, error) {
+	return t.put(item, nil)
+}
+
+func (t *Table) put(item Item, cond condition) (Item, error) {
 	t.m.Lock()
 	defer t.m.Unlock()
 	if err := validateItem(item, t.schema); err != nil {
 		return nil, err
 	}
+	k, _ := getKeyStrings(item, t.schema.PrimaryKey)
+	if err := checkCondition(cond, t.get(k)); err != nil {
+		return nil, err
+	}
+	t.own()
 	old := t.pop(item)
 	t.items = append(t.items, item)
-	_ = t.indexItem(item)
+	t.indexItem(item)
 	return old, nil
 }
 
@@ -225,30 +338,42 @@ func (t *Table) Query(k *keyCondExpr, gsi *string, forward bool, exclusiveStartK
 	return applySortKeyCond(items, k.sortCond), nil
 }
 
-func (t *Table) Update(key Item, updateExpr *updateExpr, returnValues *string) (Item, error) {
+func (t *Table) Update(key Item, updateExpr *updateExpr, cond condition, returnValues *string) (Item, error) {
 	t.m.Lock()
 	defer t.m.Unlock()
 	if err := validateKeyItem(key, t.schema); err != nil {
 		return nil, err
 	}
-	k, _ := getKeyStrings(key, t.schema.PrimaryKey)
-	item := t.get(k)
-	var returnAttr Item
-	if returnValues != nil && *returnValues == "ALL_NEW" {
-		returnAttr = item
-	} else if returnValues != nil && *returnValues == "ALL_OLD" {
-		returnAttr = Item{}
-		for k, v := range item {
-			returnAttr[k] = v
+	for _, keyPart := range keyParts(t.schema.PrimaryKey) {
+		if updateExpr.updatesAttr(keyPart.Name) {
+			return nil, errs.Errorf("%v: cannot update key attribute '%s'", ErrInvalidUpdateExpression, keyPart.Name)
 		}
 	}
-	for k, v := range updateExpr.setExpr {
-		item[k] = v
+	k, _ := getKeyStrings(key, t.schema.PrimaryKey)
+	old := t.get(k)
+	if err := checkCondition(cond, old); err != nil {
+		return nil, err
+	}
+	item, err := updateExpr.apply(key, old)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateItem(item, t.schema); err != nil {
+		return nil, err
+	}
+	t.own()
+	if old == nil {
+		t.items = append(t.items, item)
+		t.indexItem(item)
+	} else {
+		t.replace(old, item)
 	}
-	for _, k := range updateExpr.removeAttributes {
-		delete(item, k)
+	if returnValues != nil && *returnValues == "ALL_NEW" {
+		return item, nil
+	} else if returnValues != nil && *returnValues == "ALL_OLD" {
+		return old, nil
 	}
-	return returnAttr, nil
+	return nil, nil
 }
 
 func applySortKeyCond(items []Item, k *keyCond) []Item {
@@ -326,16 +451,40 @@ func (t *Table) pop(key Item) Item {
 	if old == nil {
 		return nil
 	}
-	for _, gsi := range t.schema.GSIs {
-		if hasKey(old, gsi) {
-			gsiKey, _ := getKeyStrings(old, gsi)
+	t.unindexItemByKeys(old, k)
+	t.items = t.deleteItemInSlice(t.items, k)
+	delete(t.byPrimary[k.PartitionKey], k.SortKey)
+	return old
+}
+
+// replace swaps the stored item old for item with the same primary key,
+// keeping the position of old in t.items.
+func (t *Table) replace(old, item Item) {
+	pk := t.schema.PrimaryKey
+	k, _ := getKeyStrings(old, pk)
+	for i, it := range t.items {
+		if ik, _ := getKeyStrings(it, pk); *ik == *k {
+			t.items[i] = item
+			break
+		}
+	}
+	t.byPrimary[k.PartitionKey][k.SortKey] = item
+	t.unindexItemByKeys(old, k)
+	for _, gsi := range t.schema.gsis {
+		t.indexItemByKey(item, gsi)
+	}
+}
+
+// unindexItemByKeys removes item with primary keyStrings k from the
+// primary key and Global Secondary Indexes in t.byIndex.
+func (t *Table) unindexItemByKeys(item Item, k *keyStrings) {
+	for _, gsi := range t.schema.gsis {
+		if hasKey(item, gsi) {
+			gsiKey, _ := getKeyStrings(item, gsi)
 			items := t.byIndex[gsi.Name][gsiKey.PartitionKey]
 			t.byIndex[gsi.Name][gsiKey.PartitionKey] = t.deleteItemInSlice(items, k)
 		}
 	}
-	t.items = t.deleteItemInSlice(t.items, k)
-	delete(t.byPrimary[k.PartitionKey], k.SortKey)
-	return old
 }
 
 func (t *Table) deleteItemInSlice(items []Item, delKeys *keyStrings) []Item {
@@ -370,11 +519,16 @@ func lessFunc(items []Item, item Item, key *KeyPartDef) func(int) bool {
 			return *items[i][key.Name].S >= *item[key.Name].S
 		}
 	}
+	if key.Type == "binary" {
+		return func(i int) bool {
+			return bytes.Compare(items[i][key.Name].B, item[key.Name].B) >= 0
+		}
+	}
 	// key.Type: "number"
-	f, _ := strconv.ParseFloat(*item[key.Name].N, 64)
+	n, _ := ParseNumber(*item[key.Name].N)
 	return func(i int) bool {
-		fi, _ := strconv.ParseFloat(*items[i][key.Name].N, 64)
-		return fi >= f
+		ni, _ := ParseNumber(*items[i][key.Name].N)
+		return ni.Cmp(n) >= 0
 	}
 }
 
@@ -392,9 +546,26 @@ func getKeyString(attr *dynamodb.AttributeValue, attrType string) (string, error
 		if attr.N == nil {
 			return "", errs.Errorf("%v: %v: %v, expected number", ErrInvalidKey, ErrInvalidType, attr)
 		}
-		return *attr.N, nil
+		n, err := ParseNumber(*attr.N)
+		if err != nil {
+			return "", errs.Errorf("%v: %v", ErrInvalidKey, err)
+		}
+		// canonical form, so that e.g. "1" and "1.0" are the same key.
+		return n.String(), nil
+	case "binary":
+		if attr.B == nil {
+			return "", errs.Errorf("%v: %v: %v, expected binary", ErrInvalidKey, ErrInvalidType, attr)
+		}
+		return string(attr.B), nil
+	}
+	return "", errs.Errorf("%v: %v: %s expected 'string', 'number' or 'binary'", ErrInvalidKey, ErrInvalidType, attr)
+}
+
+func keyParts(keyDef KeyDef) []KeyPartDef {
+	if keyDef.SortKey == nil {
+		return []KeyPartDef{keyDef.PartitionKey}
 	}
-	return "", errs.Errorf("%v: %v: %s expected 'string' or 'number'", ErrInvalidKey, ErrInvalidType, attr)
+	return []KeyPartDef{keyDef.PartitionKey, *keyDef.SortKey}
 }
 
 type keyStrings struct {
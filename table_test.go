@@ -127,3 +127,16 @@ func TestSliceAfterStartKeyErr(t *testing.T) {
 	_, err := sliceAfterStartKey(items, start, key)
 	requireErrIs(t, err, ErrNil)
 }
+
+func TestTablePutDelete(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	table := db.tables["product"]
+	item := Item{"id": {S: strPtr("9")}, "name": {S: strPtr("pencil")}}
+	old, err := table.Put(item)
+	require.NoError(t, err)
+	require.Nil(t, old)
+
+	old, err = table.Delete(Item{"id": {S: strPtr("9")}})
+	require.NoError(t, err)
+	require.Equal(t, item, old)
+}
@@ -0,0 +1,234 @@
+package dynamock
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"foxygo.at/s/errs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+var ErrTerraform = errors.New("invalid Terraform JSON")
+
+const tfTableType = "aws_dynamodb_table"
+
+// tfShow is the output of `terraform show -json` for a state or a plan,
+// limited to the fields used by dynamock.
+type tfShow struct {
+	Values        *tfValues `json:"values"`
+	PlannedValues *tfValues `json:"planned_values"`
+}
+
+type tfValues struct {
+	RootModule tfModule `json:"root_module"`
+}
+
+type tfModule struct {
+	Resources    []tfResource `json:"resources"`
+	ChildModules []tfModule   `json:"child_modules"`
+}
+
+type tfResource struct {
+	Address string          `json:"address"`
+	Mode    string          `json:"mode"`
+	Type    string          `json:"type"`
+	Values  json.RawMessage `json:"values"`
+}
+
+// tfTable holds the attributes of an aws_dynamodb_table resource.
+type tfTable struct {
+	Name                 *string       `json:"name"`
+	HashKey              string        `json:"hash_key"`
+	RangeKey             string        `json:"range_key"`
+	Attribute            []tfAttribute `json:"attribute"`
+	GlobalSecondaryIndex []tfIndex     `json:"global_secondary_index"`
+	LocalSecondaryIndex  []tfIndex     `json:"local_secondary_index"`
+	TTL                  []tfTTL       `json:"ttl"`
+	StreamEnabled        bool          `json:"stream_enabled"`
+	StreamViewType       string        `json:"stream_view_type"`
+}
+
+type tfAttribute struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type tfIndex struct {
+	Name     string `json:"name"`
+	HashKey  string `json:"hash_key"`
+	RangeKey string `json:"range_key"`
+}
+
+type tfTTL struct {
+	AttributeName string `json:"attribute_name"`
+	Enabled       bool   `json:"enabled"`
+}
+
+// ReadTerraformSchemas reads the managed aws_dynamodb_table resources of
+// the root module and all child modules from `terraform show -json`
+// output and returns their schemas by table name. For plans the planned
+// values are used.
+func ReadTerraformSchemas(r io.Reader) (map[string]Schema, error) {
+	tables, err := readTerraformTables(r)
+	if err != nil {
+		return nil, errs.Errorf("ReadTerraformSchemas: %v", err)
+	}
+	schemas := make(map[string]Schema, len(tables))
+	for _, t := range tables {
+		schemas[t.name] = t.schema
+	}
+	return schemas, nil
+}
+
+// NewDBFromTerraform creates a DB with an empty table for every
+// aws_dynamodb_table resource of `terraform show -json` output, see
+// ReadTerraformSchemas.
+func NewDBFromTerraform(r io.Reader) (*DB, error) {
+	db := NewDB()
+	if err := db.loadTerraform(r); err != nil {
+		return nil, errs.Errorf("NewDBFromTerraform: %v", err)
+	}
+	return db, nil
+}
+
+// LoadTerraform adds the tables of the `terraform show -json` output file
+// fname to db, see NewDBFromTerraform.
+func (db *DB) LoadTerraform(fname string) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return errs.Errorf("LoadTerraform: %v: %v", ErrTerraform, err)
+	}
+	defer f.Close()
+	if err := db.loadTerraform(f); err != nil {
+		return errs.Errorf("LoadTerraform: %v (file: '%s')", err, fname)
+	}
+	return nil
+}
+
+func (db *DB) loadTerraform(r io.Reader) error {
+	tables, err := readTerraformTables(r)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		if err := db.addTable(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readTerraformTables(r io.Reader) ([]*Table, error) {
+	show := tfShow{}
+	if err := json.NewDecoder(r).Decode(&show); err != nil {
+		return nil, errs.Errorf("%v: %v", ErrTerraform, err)
+	}
+	values := show.PlannedValues
+	if values == nil {
+		values = show.Values
+	}
+	if values == nil {
+		return nil, errs.Errorf("%v: %v: values", ErrTerraform, ErrNil)
+	}
+	return terraformModuleTables(values.RootModule)
+}
+
+func terraformModuleTables(m tfModule) ([]*Table, error) {
+	var tables []*Table
+	for _, res := range m.Resources {
+		if res.Mode != "managed" || res.Type != tfTableType {
+			continue
+		}
+		t, err := terraformTable(res.Values)
+		if err != nil {
+			return nil, errs.Errorf("%v (resource: '%s')", err, res.Address)
+		}
+		tables = append(tables, t)
+	}
+	for _, child := range m.ChildModules {
+		childTables, err := terraformModuleTables(child)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, childTables...)
+	}
+	return tables, nil
+}
+
+func terraformTable(values json.RawMessage) (*Table, error) {
+	tf := tfTable{}
+	if err := json.Unmarshal(values, &tf); err != nil {
+		return nil, errs.Errorf("%v: %v", ErrTerraform, err)
+	}
+	if tf.Name == nil {
+		return nil, errs.Errorf("%v: %v: name", ErrTerraform, ErrMissingName)
+	}
+	schema, err := SchemaFromCreateTableInput(tf.createTableInput())
+	if err != nil {
+		return nil, err
+	}
+	for _, ttl := range tf.TTL {
+		if ttl.Enabled {
+			schema.TTLAttribute = ttl.AttributeName
+		}
+	}
+	table := &Table{name: *tf.Name, schema: schema}
+	if err := validateTable(table); err != nil {
+		return nil, err
+	}
+	_ = table.index() // cannot fail without items
+	return table, nil
+}
+
+// createTableInput converts the table attributes to a CreateTable request.
+// Local secondary indexes implicitly use the table's hash key.
+func (tf *tfTable) createTableInput() *dynamodb.CreateTableInput {
+	in := &dynamodb.CreateTableInput{
+		TableName: tf.Name,
+		KeySchema: tfKeySchema(tf.HashKey, tf.RangeKey),
+	}
+	for _, a := range tf.Attribute {
+		in.AttributeDefinitions = append(in.AttributeDefinitions, &dynamodb.AttributeDefinition{
+			AttributeName: aws.String(a.Name),
+			AttributeType: aws.String(a.Type),
+		})
+	}
+	for _, gsi := range tf.GlobalSecondaryIndex {
+		in.GlobalSecondaryIndexes = append(in.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndex{
+			IndexName: aws.String(gsi.Name),
+			KeySchema: tfKeySchema(gsi.HashKey, gsi.RangeKey),
+		})
+	}
+	for _, lsi := range tf.LocalSecondaryIndex {
+		in.LocalSecondaryIndexes = append(in.LocalSecondaryIndexes, &dynamodb.LocalSecondaryIndex{
+			IndexName: aws.String(lsi.Name),
+			KeySchema: tfKeySchema(tf.HashKey, lsi.RangeKey),
+		})
+	}
+	if tf.StreamEnabled {
+		in.StreamSpecification = &dynamodb.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: aws.String(tf.StreamViewType),
+		}
+	}
+	return in
+}
+
+// tfKeySchema returns the key schema for a hash key and an optional range
+// key, which Terraform represents as an empty string or null if unset.
+func tfKeySchema(hashKey, rangeKey string) []*dynamodb.KeySchemaElement {
+	keySchema := []*dynamodb.KeySchemaElement{{
+		AttributeName: aws.String(hashKey),
+		KeyType:       aws.String(dynamodb.KeyTypeHash),
+	}}
+	if rangeKey != "" {
+		keySchema = append(keySchema, &dynamodb.KeySchemaElement{
+			AttributeName: aws.String(rangeKey),
+			KeyType:       aws.String(dynamodb.KeyTypeRange),
+		})
+	}
+	return keySchema
+}
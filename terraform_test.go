@@ -0,0 +1,104 @@
+package dynamock
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTerraformSchemas(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "terraform.json"))
+	require.NoError(t, err)
+	defer f.Close()
+	schemas, err := ReadTerraformSchemas(f)
+	require.NoError(t, err)
+	require.Len(t, schemas, 2)
+
+	want := Schema{
+		PrimaryKey: KeyDef{
+			PartitionKey: KeyPartDef{Name: "customer", Type: "string"},
+			SortKey:      &KeyPartDef{Name: "created", Type: "number"},
+		},
+		GSIs: []KeyDef{{Name: "byStatus", PartitionKey: KeyPartDef{Name: "status", Type: "string"}}},
+		LSIs: []KeyDef{{
+			Name:         "byCustomerStatus",
+			PartitionKey: KeyPartDef{Name: "customer", Type: "string"},
+			SortKey:      &KeyPartDef{Name: "status", Type: "string"},
+		}},
+		TTLAttribute:   "expires",
+		StreamViewType: "NEW_IMAGE",
+	}
+	order := schemas["order"]
+	order.gsis = nil
+	require.Equal(t, want, order)
+
+	session := schemas["session"]
+	session.gsis = nil
+	require.Equal(t, Schema{PrimaryKey: KeyDef{PartitionKey: KeyPartDef{Name: "token", Type: "binary"}}}, session)
+}
+
+func TestLoadTerraform(t *testing.T) {
+	db := NewDB()
+	require.NoError(t, db.LoadTerraform(filepath.Join("testdata", "terraform.json")))
+	require.Equal(t, []string{"order", "session"}, db.tableNames)
+	require.Empty(t, db.tables["order"].items)
+
+	err := db.LoadTerraform(filepath.Join("testdata", "terraform.json"))
+	requireErrIs(t, err, ErrDuplicate)
+
+	err = db.LoadTerraform(filepath.Join("testdata", "MISSING.json"))
+	requireErrIs(t, err, ErrTerraform)
+}
+
+func TestNewDBFromTerraformPlan(t *testing.T) {
+	plan := `{
+  "planned_values": {"root_module": {"resources": [{
+    "address": "aws_dynamodb_table.product", "mode": "managed", "type": "aws_dynamodb_table",
+    "values": {"name": "product", "hash_key": "id", "attribute": [{"name": "id", "type": "S"}]}
+  }]}},
+  "prior_state": {"values": {"root_module": {}}}
+}`
+	db, err := NewDBFromTerraform(strings.NewReader(plan))
+	require.NoError(t, err)
+	require.Equal(t, []string{"product"}, db.tableNames)
+}
+
+func TestTerraformErr(t *testing.T) {
+	resource := func(values string) string {
+		return `{"values": {"root_module": {"child_modules": [{"resources": [{
+  "address": "aws_dynamodb_table.t", "mode": "managed", "type": "aws_dynamodb_table", "values": ` + values + `}]}]}}}`
+	}
+	testCases := map[string]struct {
+		in   string
+		want error
+	}{
+		"bad_json":     {in: "{", want: ErrTerraform},
+		"no_values":    {in: `{"format_version": "1.0"}`, want: ErrNil},
+		"bad_values":   {in: resource(`{"name": 1}`), want: ErrTerraform},
+		"unknown_name": {in: resource(`{"hash_key": "id", "attribute": [{"name": "id", "type": "S"}]}`), want: ErrMissingName},
+		"no_attribute": {in: resource(`{"name": "t", "hash_key": "id"}`), want: ErrMissingType},
+		"bad_type":     {in: resource(`{"name": "t", "hash_key": "id", "attribute": [{"name": "id", "type": "BOOL"}]}`), want: ErrUnknownType},
+		"bad_lsi": {
+			in:   resource(`{"name": "t", "hash_key": "id", "attribute": [{"name": "id", "type": "S"}], "local_secondary_index": [{"name": "l"}]}`),
+			want: ErrSchemaValidation,
+		},
+		"duplicate": {
+			in: `{"values": {"root_module": {"resources": [
+  {"mode": "managed", "type": "aws_dynamodb_table", "values": {"name": "t", "hash_key": "id", "attribute": [{"name": "id", "type": "S"}]}},
+  {"mode": "managed", "type": "aws_dynamodb_table", "values": {"name": "t", "hash_key": "id", "attribute": [{"name": "id", "type": "S"}]}}]}}}`,
+			want: ErrDuplicate,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := NewDBFromTerraform(strings.NewReader(tc.in))
+			requireErrIs(t, err, tc.want)
+		})
+	}
+	_, err := ReadTerraformSchemas(strings.NewReader("{"))
+	requireErrIs(t, err, ErrTerraform)
+}
@@ -0,0 +1,227 @@
+package dynamock
+
+import (
+	"encoding/base64"
+
+	"foxygo.at/s/errs"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Fixture formats for JSONTable.Format.
+//
+// In the plain JSON format items are ordinary JSON objects marshaled with
+// dynamodbattribute, e.g. {"id": 1, "tags": ["a", "b"]}.
+//
+// In the DynamoDB JSON format items use the AttributeValue wire format,
+// e.g. {"id": {"N": "1"}, "tags": {"SS": ["a", "b"]}}, so that sets,
+// binaries and numbers are represented exactly.
+const (
+	formatJSON         = "json"
+	formatDynamoDBJSON = "dynamodb"
+)
+
+var typeDescriptors = map[string]bool{
+	"S": true, "N": true, "B": true, "BOOL": true, "NULL": true,
+	"SS": true, "NS": true, "BS": true, "L": true, "M": true,
+}
+
+func validateFormat(format string) error {
+	switch format {
+	case "", formatJSON, formatDynamoDBJSON:
+		return nil
+	}
+	return errs.Errorf("%v: format: %s", ErrUnknownType, format)
+}
+
+// detectFormat returns formatDynamoDBJSON if all attributes of all items
+// are objects with a single type descriptor key, e.g. {"S": "abc"}, and
+// formatJSON otherwise.
+func detectFormat(items []map[string]interface{}) string {
+	if len(items) == 0 {
+		return formatJSON
+	}
+	for _, item := range items {
+		for _, v := range item {
+			m, ok := v.(map[string]interface{})
+			if !ok || len(m) != 1 {
+				return formatJSON
+			}
+			for k := range m {
+				if !typeDescriptors[k] {
+					return formatJSON
+				}
+			}
+		}
+	}
+	return formatDynamoDBJSON
+}
+
+// decodeTypedItem converts an item decoded from DynamoDB JSON.
+func decodeTypedItem(item map[string]interface{}) (Item, error) {
+	result := Item{}
+	for name, v := range item {
+		av, err := decodeTypedAttr(v)
+		if err != nil {
+			return nil, errs.Errorf("%v: attribute '%s': %v", ErrItemValidation, name, err)
+		}
+		result[name] = av
+	}
+	return result, nil
+}
+
+func decodeTypedAttr(v interface{}) (*dynamodb.AttributeValue, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return nil, errs.Errorf("%v: expected object with single type descriptor, got '%v'", ErrInvalidAttr, v)
+	}
+	var typ string
+	var val interface{}
+	for k, v := range m {
+		typ, val = k, v
+	}
+	switch typ {
+	case "S", "N":
+		s, err := typedString(val)
+		if err != nil {
+			return nil, err
+		}
+		if typ == "N" {
+			return &dynamodb.AttributeValue{N: s}, nil
+		}
+		return &dynamodb.AttributeValue{S: s}, nil
+	case "B":
+		b, err := typedBinary(val)
+		if err != nil {
+			return nil, err
+		}
+		return &dynamodb.AttributeValue{B: b}, nil
+	case "BOOL", "NULL":
+		b, ok := val.(bool)
+		if !ok {
+			return nil, errs.Errorf("%v: %v: expected bool for %s", ErrInvalidAttr, ErrInvalidType, typ)
+		}
+		if typ == "NULL" {
+			return &dynamodb.AttributeValue{NULL: &b}, nil
+		}
+		return &dynamodb.AttributeValue{BOOL: &b}, nil
+	case "SS", "NS", "BS", "L":
+		return decodeTypedList(typ, val)
+	case "M":
+		vals, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, errs.Errorf("%v: %v: expected object for M", ErrInvalidAttr, ErrInvalidType)
+		}
+		av := &dynamodb.AttributeValue{M: Item{}}
+		for k, v := range vals {
+			elem, err := decodeTypedAttr(v)
+			if err != nil {
+				return nil, err
+			}
+			av.M[k] = elem
+		}
+		return av, nil
+	}
+	return nil, errs.Errorf("%v: unknown type descriptor '%s'", ErrInvalidAttr, typ)
+}
+
+func decodeTypedList(typ string, val interface{}) (*dynamodb.AttributeValue, error) {
+	vals, ok := val.([]interface{})
+	if !ok {
+		return nil, errs.Errorf("%v: %v: expected array for %s", ErrInvalidAttr, ErrInvalidType, typ)
+	}
+	av := &dynamodb.AttributeValue{}
+	switch typ {
+	case "SS":
+		av.SS = []*string{}
+	case "NS":
+		av.NS = []*string{}
+	case "BS":
+		av.BS = [][]byte{}
+	case "L":
+		av.L = []*dynamodb.AttributeValue{}
+	}
+	for _, v := range vals {
+		switch typ {
+		case "SS", "NS":
+			s, err := typedString(v)
+			if err != nil {
+				return nil, err
+			}
+			if typ == "SS" {
+				av.SS = append(av.SS, s)
+			} else {
+				av.NS = append(av.NS, s)
+			}
+		case "BS":
+			b, err := typedBinary(v)
+			if err != nil {
+				return nil, err
+			}
+			av.BS = append(av.BS, b)
+		case "L":
+			elem, err := decodeTypedAttr(v)
+			if err != nil {
+				return nil, err
+			}
+			av.L = append(av.L, elem)
+		}
+	}
+	return av, nil
+}
+
+func typedString(v interface{}) (*string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errs.Errorf("%v: %v: expected string, got '%v'", ErrInvalidAttr, ErrInvalidType, v)
+	}
+	return &s, nil
+}
+
+func typedBinary(v interface{}) ([]byte, error) {
+	s, err := typedString(v)
+	if err != nil {
+		return nil, err
+	}
+	b, err := base64.StdEncoding.DecodeString(*s)
+	if err != nil {
+		return nil, errs.Errorf("%v: %v: base64 value: %v", ErrInvalidAttr, ErrInvalidType, err)
+	}
+	return b, nil
+}
+
+// encodeTypedItem converts item to DynamoDB JSON.
+func encodeTypedItem(item Item) map[string]interface{} {
+	result := make(map[string]interface{}, len(item))
+	for name, av := range item {
+		result[name] = encodeTypedAttr(av)
+	}
+	return result
+}
+
+func encodeTypedAttr(av *dynamodb.AttributeValue) map[string]interface{} {
+	switch {
+	case av.S != nil:
+		return map[string]interface{}{"S": *av.S}
+	case av.N != nil:
+		return map[string]interface{}{"N": *av.N}
+	case av.B != nil:
+		return map[string]interface{}{"B": av.B}
+	case av.BOOL != nil:
+		return map[string]interface{}{"BOOL": *av.BOOL}
+	case av.NULL != nil:
+		return map[string]interface{}{"NULL": *av.NULL}
+	case av.SS != nil:
+		return map[string]interface{}{"SS": av.SS}
+	case av.NS != nil:
+		return map[string]interface{}{"NS": av.NS}
+	case av.BS != nil:
+		return map[string]interface{}{"BS": av.BS}
+	case av.L != nil:
+		l := make([]interface{}, len(av.L))
+		for i, elem := range av.L {
+			l[i] = encodeTypedAttr(elem)
+		}
+		return map[string]interface{}{"L": l}
+	}
+	return map[string]interface{}{"M": encodeTypedItem(av.M)}
+}
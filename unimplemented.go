@@ -12,553 +12,596 @@ var ErrUnimpl = fmt.Errorf("not implemented")
 
 // UnimplementedDB implements the dynamodbiface.DynamoDBAPI interface by
 // returning an ErrUnimpl for every method call that can return an error.
+// Calls are recorded like DB calls, see DB.Record.
 type UnimplementedDB struct {
+	recorder *Recorder
 }
 
-func (*UnimplementedDB) BatchGetItem(_ *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) BatchGetItem(in *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	return nil, u.record("BatchGetItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) BatchGetItemWithContext(_ aws.Context, _ *dynamodb.BatchGetItemInput, _ ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) BatchGetItemWithContext(_ aws.Context, in *dynamodb.BatchGetItemInput, _ ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	return nil, u.record("BatchGetItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) BatchGetItemRequest(_ *dynamodb.BatchGetItemInput) (*request.Request, *dynamodb.BatchGetItemOutput) {
+func (u *UnimplementedDB) BatchGetItemRequest(in *dynamodb.BatchGetItemInput) (*request.Request, *dynamodb.BatchGetItemOutput) {
+	u.record("BatchGetItem", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) BatchGetItemPages(_ *dynamodb.BatchGetItemInput, _ func(*dynamodb.BatchGetItemOutput, bool) bool) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) BatchGetItemPages(in *dynamodb.BatchGetItemInput, _ func(*dynamodb.BatchGetItemOutput, bool) bool) error {
+	return u.record("BatchGetItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) BatchGetItemPagesWithContext(_ aws.Context, _ *dynamodb.BatchGetItemInput, _ func(*dynamodb.BatchGetItemOutput, bool) bool, _ ...request.Option) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) BatchGetItemPagesWithContext(_ aws.Context, in *dynamodb.BatchGetItemInput, _ func(*dynamodb.BatchGetItemOutput, bool) bool, _ ...request.Option) error {
+	return u.record("BatchGetItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) BatchWriteItem(_ *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) BatchWriteItem(in *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	return nil, u.record("BatchWriteItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) BatchWriteItemWithContext(_ aws.Context, _ *dynamodb.BatchWriteItemInput, _ ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) BatchWriteItemWithContext(_ aws.Context, in *dynamodb.BatchWriteItemInput, _ ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	return nil, u.record("BatchWriteItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) BatchWriteItemRequest(_ *dynamodb.BatchWriteItemInput) (*request.Request, *dynamodb.BatchWriteItemOutput) {
+func (u *UnimplementedDB) BatchWriteItemRequest(in *dynamodb.BatchWriteItemInput) (*request.Request, *dynamodb.BatchWriteItemOutput) {
+	u.record("BatchWriteItem", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) CreateBackup(_ *dynamodb.CreateBackupInput) (*dynamodb.CreateBackupOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) CreateBackup(in *dynamodb.CreateBackupInput) (*dynamodb.CreateBackupOutput, error) {
+	return nil, u.record("CreateBackup", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) CreateBackupWithContext(_ aws.Context, _ *dynamodb.CreateBackupInput, _ ...request.Option) (*dynamodb.CreateBackupOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) CreateBackupWithContext(_ aws.Context, in *dynamodb.CreateBackupInput, _ ...request.Option) (*dynamodb.CreateBackupOutput, error) {
+	return nil, u.record("CreateBackup", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) CreateBackupRequest(_ *dynamodb.CreateBackupInput) (*request.Request, *dynamodb.CreateBackupOutput) {
+func (u *UnimplementedDB) CreateBackupRequest(in *dynamodb.CreateBackupInput) (*request.Request, *dynamodb.CreateBackupOutput) {
+	u.record("CreateBackup", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) CreateGlobalTable(_ *dynamodb.CreateGlobalTableInput) (*dynamodb.CreateGlobalTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) CreateGlobalTable(in *dynamodb.CreateGlobalTableInput) (*dynamodb.CreateGlobalTableOutput, error) {
+	return nil, u.record("CreateGlobalTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) CreateGlobalTableWithContext(_ aws.Context, _ *dynamodb.CreateGlobalTableInput, _ ...request.Option) (*dynamodb.CreateGlobalTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) CreateGlobalTableWithContext(_ aws.Context, in *dynamodb.CreateGlobalTableInput, _ ...request.Option) (*dynamodb.CreateGlobalTableOutput, error) {
+	return nil, u.record("CreateGlobalTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) CreateGlobalTableRequest(_ *dynamodb.CreateGlobalTableInput) (*request.Request, *dynamodb.CreateGlobalTableOutput) {
+func (u *UnimplementedDB) CreateGlobalTableRequest(in *dynamodb.CreateGlobalTableInput) (*request.Request, *dynamodb.CreateGlobalTableOutput) {
+	u.record("CreateGlobalTable", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) CreateTable(_ *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) CreateTable(in *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	return nil, u.record("CreateTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) CreateTableWithContext(_ aws.Context, _ *dynamodb.CreateTableInput, _ ...request.Option) (*dynamodb.CreateTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) CreateTableWithContext(_ aws.Context, in *dynamodb.CreateTableInput, _ ...request.Option) (*dynamodb.CreateTableOutput, error) {
+	return nil, u.record("CreateTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) CreateTableRequest(_ *dynamodb.CreateTableInput) (*request.Request, *dynamodb.CreateTableOutput) {
+func (u *UnimplementedDB) CreateTableRequest(in *dynamodb.CreateTableInput) (*request.Request, *dynamodb.CreateTableOutput) {
+	u.record("CreateTable", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DeleteBackup(_ *dynamodb.DeleteBackupInput) (*dynamodb.DeleteBackupOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DeleteBackup(in *dynamodb.DeleteBackupInput) (*dynamodb.DeleteBackupOutput, error) {
+	return nil, u.record("DeleteBackup", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DeleteBackupWithContext(_ aws.Context, _ *dynamodb.DeleteBackupInput, _ ...request.Option) (*dynamodb.DeleteBackupOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DeleteBackupWithContext(_ aws.Context, in *dynamodb.DeleteBackupInput, _ ...request.Option) (*dynamodb.DeleteBackupOutput, error) {
+	return nil, u.record("DeleteBackup", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DeleteBackupRequest(_ *dynamodb.DeleteBackupInput) (*request.Request, *dynamodb.DeleteBackupOutput) {
+func (u *UnimplementedDB) DeleteBackupRequest(in *dynamodb.DeleteBackupInput) (*request.Request, *dynamodb.DeleteBackupOutput) {
+	u.record("DeleteBackup", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DeleteItem(_ *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DeleteItem(in *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	return nil, u.record("DeleteItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DeleteItemWithContext(_ aws.Context, _ *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DeleteItemWithContext(_ aws.Context, in *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	return nil, u.record("DeleteItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DeleteItemRequest(_ *dynamodb.DeleteItemInput) (*request.Request, *dynamodb.DeleteItemOutput) {
+func (u *UnimplementedDB) DeleteItemRequest(in *dynamodb.DeleteItemInput) (*request.Request, *dynamodb.DeleteItemOutput) {
+	u.record("DeleteItem", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DeleteTable(_ *dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DeleteTable(in *dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error) {
+	return nil, u.record("DeleteTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DeleteTableWithContext(_ aws.Context, _ *dynamodb.DeleteTableInput, _ ...request.Option) (*dynamodb.DeleteTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DeleteTableWithContext(_ aws.Context, in *dynamodb.DeleteTableInput, _ ...request.Option) (*dynamodb.DeleteTableOutput, error) {
+	return nil, u.record("DeleteTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DeleteTableRequest(_ *dynamodb.DeleteTableInput) (*request.Request, *dynamodb.DeleteTableOutput) {
+func (u *UnimplementedDB) DeleteTableRequest(in *dynamodb.DeleteTableInput) (*request.Request, *dynamodb.DeleteTableOutput) {
+	u.record("DeleteTable", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DescribeBackup(_ *dynamodb.DescribeBackupInput) (*dynamodb.DescribeBackupOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeBackup(in *dynamodb.DescribeBackupInput) (*dynamodb.DescribeBackupOutput, error) {
+	return nil, u.record("DescribeBackup", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeBackupWithContext(_ aws.Context, _ *dynamodb.DescribeBackupInput, _ ...request.Option) (*dynamodb.DescribeBackupOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeBackupWithContext(_ aws.Context, in *dynamodb.DescribeBackupInput, _ ...request.Option) (*dynamodb.DescribeBackupOutput, error) {
+	return nil, u.record("DescribeBackup", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeBackupRequest(_ *dynamodb.DescribeBackupInput) (*request.Request, *dynamodb.DescribeBackupOutput) {
+func (u *UnimplementedDB) DescribeBackupRequest(in *dynamodb.DescribeBackupInput) (*request.Request, *dynamodb.DescribeBackupOutput) {
+	u.record("DescribeBackup", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DescribeContinuousBackups(_ *dynamodb.DescribeContinuousBackupsInput) (*dynamodb.DescribeContinuousBackupsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeContinuousBackups(in *dynamodb.DescribeContinuousBackupsInput) (*dynamodb.DescribeContinuousBackupsOutput, error) {
+	return nil, u.record("DescribeContinuousBackups", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeContinuousBackupsWithContext(_ aws.Context, _ *dynamodb.DescribeContinuousBackupsInput, _ ...request.Option) (*dynamodb.DescribeContinuousBackupsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeContinuousBackupsWithContext(_ aws.Context, in *dynamodb.DescribeContinuousBackupsInput, _ ...request.Option) (*dynamodb.DescribeContinuousBackupsOutput, error) {
+	return nil, u.record("DescribeContinuousBackups", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeContinuousBackupsRequest(_ *dynamodb.DescribeContinuousBackupsInput) (*request.Request, *dynamodb.DescribeContinuousBackupsOutput) {
+func (u *UnimplementedDB) DescribeContinuousBackupsRequest(in *dynamodb.DescribeContinuousBackupsInput) (*request.Request, *dynamodb.DescribeContinuousBackupsOutput) {
+	u.record("DescribeContinuousBackups", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DescribeContributorInsights(_ *dynamodb.DescribeContributorInsightsInput) (*dynamodb.DescribeContributorInsightsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeContributorInsights(in *dynamodb.DescribeContributorInsightsInput) (*dynamodb.DescribeContributorInsightsOutput, error) {
+	return nil, u.record("DescribeContributorInsights", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeContributorInsightsWithContext(_ aws.Context, _ *dynamodb.DescribeContributorInsightsInput, _ ...request.Option) (*dynamodb.DescribeContributorInsightsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeContributorInsightsWithContext(_ aws.Context, in *dynamodb.DescribeContributorInsightsInput, _ ...request.Option) (*dynamodb.DescribeContributorInsightsOutput, error) {
+	return nil, u.record("DescribeContributorInsights", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeContributorInsightsRequest(_ *dynamodb.DescribeContributorInsightsInput) (*request.Request, *dynamodb.DescribeContributorInsightsOutput) {
+func (u *UnimplementedDB) DescribeContributorInsightsRequest(in *dynamodb.DescribeContributorInsightsInput) (*request.Request, *dynamodb.DescribeContributorInsightsOutput) {
+	u.record("DescribeContributorInsights", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DescribeEndpoints(_ *dynamodb.DescribeEndpointsInput) (*dynamodb.DescribeEndpointsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeEndpoints(in *dynamodb.DescribeEndpointsInput) (*dynamodb.DescribeEndpointsOutput, error) {
+	return nil, u.record("DescribeEndpoints", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeEndpointsWithContext(_ aws.Context, _ *dynamodb.DescribeEndpointsInput, _ ...request.Option) (*dynamodb.DescribeEndpointsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeEndpointsWithContext(_ aws.Context, in *dynamodb.DescribeEndpointsInput, _ ...request.Option) (*dynamodb.DescribeEndpointsOutput, error) {
+	return nil, u.record("DescribeEndpoints", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeEndpointsRequest(_ *dynamodb.DescribeEndpointsInput) (*request.Request, *dynamodb.DescribeEndpointsOutput) {
+func (u *UnimplementedDB) DescribeEndpointsRequest(in *dynamodb.DescribeEndpointsInput) (*request.Request, *dynamodb.DescribeEndpointsOutput) {
+	u.record("DescribeEndpoints", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DescribeGlobalTable(_ *dynamodb.DescribeGlobalTableInput) (*dynamodb.DescribeGlobalTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeGlobalTable(in *dynamodb.DescribeGlobalTableInput) (*dynamodb.DescribeGlobalTableOutput, error) {
+	return nil, u.record("DescribeGlobalTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeGlobalTableWithContext(_ aws.Context, _ *dynamodb.DescribeGlobalTableInput, _ ...request.Option) (*dynamodb.DescribeGlobalTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeGlobalTableWithContext(_ aws.Context, in *dynamodb.DescribeGlobalTableInput, _ ...request.Option) (*dynamodb.DescribeGlobalTableOutput, error) {
+	return nil, u.record("DescribeGlobalTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeGlobalTableRequest(_ *dynamodb.DescribeGlobalTableInput) (*request.Request, *dynamodb.DescribeGlobalTableOutput) {
+func (u *UnimplementedDB) DescribeGlobalTableRequest(in *dynamodb.DescribeGlobalTableInput) (*request.Request, *dynamodb.DescribeGlobalTableOutput) {
+	u.record("DescribeGlobalTable", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DescribeGlobalTableSettings(_ *dynamodb.DescribeGlobalTableSettingsInput) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeGlobalTableSettings(in *dynamodb.DescribeGlobalTableSettingsInput) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
+	return nil, u.record("DescribeGlobalTableSettings", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeGlobalTableSettingsWithContext(_ aws.Context, _ *dynamodb.DescribeGlobalTableSettingsInput, _ ...request.Option) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeGlobalTableSettingsWithContext(_ aws.Context, in *dynamodb.DescribeGlobalTableSettingsInput, _ ...request.Option) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
+	return nil, u.record("DescribeGlobalTableSettings", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeGlobalTableSettingsRequest(_ *dynamodb.DescribeGlobalTableSettingsInput) (*request.Request, *dynamodb.DescribeGlobalTableSettingsOutput) {
+func (u *UnimplementedDB) DescribeGlobalTableSettingsRequest(in *dynamodb.DescribeGlobalTableSettingsInput) (*request.Request, *dynamodb.DescribeGlobalTableSettingsOutput) {
+	u.record("DescribeGlobalTableSettings", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DescribeLimits(_ *dynamodb.DescribeLimitsInput) (*dynamodb.DescribeLimitsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeLimits(in *dynamodb.DescribeLimitsInput) (*dynamodb.DescribeLimitsOutput, error) {
+	return nil, u.record("DescribeLimits", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeLimitsWithContext(_ aws.Context, _ *dynamodb.DescribeLimitsInput, _ ...request.Option) (*dynamodb.DescribeLimitsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeLimitsWithContext(_ aws.Context, in *dynamodb.DescribeLimitsInput, _ ...request.Option) (*dynamodb.DescribeLimitsOutput, error) {
+	return nil, u.record("DescribeLimits", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeLimitsRequest(_ *dynamodb.DescribeLimitsInput) (*request.Request, *dynamodb.DescribeLimitsOutput) {
+func (u *UnimplementedDB) DescribeLimitsRequest(in *dynamodb.DescribeLimitsInput) (*request.Request, *dynamodb.DescribeLimitsOutput) {
+	u.record("DescribeLimits", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DescribeTable(_ *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeTable(in *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	return nil, u.record("DescribeTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeTableWithContext(_ aws.Context, _ *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeTableWithContext(_ aws.Context, in *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	return nil, u.record("DescribeTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeTableRequest(_ *dynamodb.DescribeTableInput) (*request.Request, *dynamodb.DescribeTableOutput) {
+func (u *UnimplementedDB) DescribeTableRequest(in *dynamodb.DescribeTableInput) (*request.Request, *dynamodb.DescribeTableOutput) {
+	u.record("DescribeTable", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DescribeTableReplicaAutoScaling(_ *dynamodb.DescribeTableReplicaAutoScalingInput) (*dynamodb.DescribeTableReplicaAutoScalingOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeTableReplicaAutoScaling(in *dynamodb.DescribeTableReplicaAutoScalingInput) (*dynamodb.DescribeTableReplicaAutoScalingOutput, error) {
+	return nil, u.record("DescribeTableReplicaAutoScaling", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeTableReplicaAutoScalingWithContext(_ aws.Context, _ *dynamodb.DescribeTableReplicaAutoScalingInput, _ ...request.Option) (*dynamodb.DescribeTableReplicaAutoScalingOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeTableReplicaAutoScalingWithContext(_ aws.Context, in *dynamodb.DescribeTableReplicaAutoScalingInput, _ ...request.Option) (*dynamodb.DescribeTableReplicaAutoScalingOutput, error) {
+	return nil, u.record("DescribeTableReplicaAutoScaling", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeTableReplicaAutoScalingRequest(_ *dynamodb.DescribeTableReplicaAutoScalingInput) (*request.Request, *dynamodb.DescribeTableReplicaAutoScalingOutput) {
+func (u *UnimplementedDB) DescribeTableReplicaAutoScalingRequest(in *dynamodb.DescribeTableReplicaAutoScalingInput) (*request.Request, *dynamodb.DescribeTableReplicaAutoScalingOutput) {
+	u.record("DescribeTableReplicaAutoScaling", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) DescribeTimeToLive(_ *dynamodb.DescribeTimeToLiveInput) (*dynamodb.DescribeTimeToLiveOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeTimeToLive(in *dynamodb.DescribeTimeToLiveInput) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	return nil, u.record("DescribeTimeToLive", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeTimeToLiveWithContext(_ aws.Context, _ *dynamodb.DescribeTimeToLiveInput, _ ...request.Option) (*dynamodb.DescribeTimeToLiveOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) DescribeTimeToLiveWithContext(_ aws.Context, in *dynamodb.DescribeTimeToLiveInput, _ ...request.Option) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	return nil, u.record("DescribeTimeToLive", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) DescribeTimeToLiveRequest(_ *dynamodb.DescribeTimeToLiveInput) (*request.Request, *dynamodb.DescribeTimeToLiveOutput) {
+func (u *UnimplementedDB) DescribeTimeToLiveRequest(in *dynamodb.DescribeTimeToLiveInput) (*request.Request, *dynamodb.DescribeTimeToLiveOutput) {
+	u.record("DescribeTimeToLive", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) GetItem(_ *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) GetItem(in *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return nil, u.record("GetItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) GetItemWithContext(_ aws.Context, _ *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) GetItemWithContext(_ aws.Context, in *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	return nil, u.record("GetItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) GetItemRequest(_ *dynamodb.GetItemInput) (*request.Request, *dynamodb.GetItemOutput) {
+func (u *UnimplementedDB) GetItemRequest(in *dynamodb.GetItemInput) (*request.Request, *dynamodb.GetItemOutput) {
+	u.record("GetItem", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) ListBackups(_ *dynamodb.ListBackupsInput) (*dynamodb.ListBackupsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) ListBackups(in *dynamodb.ListBackupsInput) (*dynamodb.ListBackupsOutput, error) {
+	return nil, u.record("ListBackups", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListBackupsWithContext(_ aws.Context, _ *dynamodb.ListBackupsInput, _ ...request.Option) (*dynamodb.ListBackupsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) ListBackupsWithContext(_ aws.Context, in *dynamodb.ListBackupsInput, _ ...request.Option) (*dynamodb.ListBackupsOutput, error) {
+	return nil, u.record("ListBackups", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListBackupsRequest(_ *dynamodb.ListBackupsInput) (*request.Request, *dynamodb.ListBackupsOutput) {
+func (u *UnimplementedDB) ListBackupsRequest(in *dynamodb.ListBackupsInput) (*request.Request, *dynamodb.ListBackupsOutput) {
+	u.record("ListBackups", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) ListContributorInsights(_ *dynamodb.ListContributorInsightsInput) (*dynamodb.ListContributorInsightsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) ListContributorInsights(in *dynamodb.ListContributorInsightsInput) (*dynamodb.ListContributorInsightsOutput, error) {
+	return nil, u.record("ListContributorInsights", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListContributorInsightsWithContext(_ aws.Context, _ *dynamodb.ListContributorInsightsInput, _ ...request.Option) (*dynamodb.ListContributorInsightsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) ListContributorInsightsWithContext(_ aws.Context, in *dynamodb.ListContributorInsightsInput, _ ...request.Option) (*dynamodb.ListContributorInsightsOutput, error) {
+	return nil, u.record("ListContributorInsights", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListContributorInsightsRequest(_ *dynamodb.ListContributorInsightsInput) (*request.Request, *dynamodb.ListContributorInsightsOutput) {
+func (u *UnimplementedDB) ListContributorInsightsRequest(in *dynamodb.ListContributorInsightsInput) (*request.Request, *dynamodb.ListContributorInsightsOutput) {
+	u.record("ListContributorInsights", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) ListContributorInsightsPages(_ *dynamodb.ListContributorInsightsInput, _ func(*dynamodb.ListContributorInsightsOutput, bool) bool) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) ListContributorInsightsPages(in *dynamodb.ListContributorInsightsInput, _ func(*dynamodb.ListContributorInsightsOutput, bool) bool) error {
+	return u.record("ListContributorInsights", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListContributorInsightsPagesWithContext(_ aws.Context, _ *dynamodb.ListContributorInsightsInput, _ func(*dynamodb.ListContributorInsightsOutput, bool) bool, _ ...request.Option) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) ListContributorInsightsPagesWithContext(_ aws.Context, in *dynamodb.ListContributorInsightsInput, _ func(*dynamodb.ListContributorInsightsOutput, bool) bool, _ ...request.Option) error {
+	return u.record("ListContributorInsights", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListGlobalTables(_ *dynamodb.ListGlobalTablesInput) (*dynamodb.ListGlobalTablesOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) ListGlobalTables(in *dynamodb.ListGlobalTablesInput) (*dynamodb.ListGlobalTablesOutput, error) {
+	return nil, u.record("ListGlobalTables", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListGlobalTablesWithContext(_ aws.Context, _ *dynamodb.ListGlobalTablesInput, _ ...request.Option) (*dynamodb.ListGlobalTablesOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) ListGlobalTablesWithContext(_ aws.Context, in *dynamodb.ListGlobalTablesInput, _ ...request.Option) (*dynamodb.ListGlobalTablesOutput, error) {
+	return nil, u.record("ListGlobalTables", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListGlobalTablesRequest(_ *dynamodb.ListGlobalTablesInput) (*request.Request, *dynamodb.ListGlobalTablesOutput) {
+func (u *UnimplementedDB) ListGlobalTablesRequest(in *dynamodb.ListGlobalTablesInput) (*request.Request, *dynamodb.ListGlobalTablesOutput) {
+	u.record("ListGlobalTables", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) ListTables(_ *dynamodb.ListTablesInput) (*dynamodb.ListTablesOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) ListTables(in *dynamodb.ListTablesInput) (*dynamodb.ListTablesOutput, error) {
+	return nil, u.record("ListTables", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListTablesWithContext(_ aws.Context, _ *dynamodb.ListTablesInput, _ ...request.Option) (*dynamodb.ListTablesOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) ListTablesWithContext(_ aws.Context, in *dynamodb.ListTablesInput, _ ...request.Option) (*dynamodb.ListTablesOutput, error) {
+	return nil, u.record("ListTables", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListTablesRequest(_ *dynamodb.ListTablesInput) (*request.Request, *dynamodb.ListTablesOutput) {
+func (u *UnimplementedDB) ListTablesRequest(in *dynamodb.ListTablesInput) (*request.Request, *dynamodb.ListTablesOutput) {
+	u.record("ListTables", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) ListTablesPages(_ *dynamodb.ListTablesInput, _ func(*dynamodb.ListTablesOutput, bool) bool) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) ListTablesPages(in *dynamodb.ListTablesInput, _ func(*dynamodb.ListTablesOutput, bool) bool) error {
+	return u.record("ListTables", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListTablesPagesWithContext(_ aws.Context, _ *dynamodb.ListTablesInput, _ func(*dynamodb.ListTablesOutput, bool) bool, _ ...request.Option) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) ListTablesPagesWithContext(_ aws.Context, in *dynamodb.ListTablesInput, _ func(*dynamodb.ListTablesOutput, bool) bool, _ ...request.Option) error {
+	return u.record("ListTables", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListTagsOfResource(_ *dynamodb.ListTagsOfResourceInput) (*dynamodb.ListTagsOfResourceOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) ListTagsOfResource(in *dynamodb.ListTagsOfResourceInput) (*dynamodb.ListTagsOfResourceOutput, error) {
+	return nil, u.record("ListTagsOfResource", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListTagsOfResourceWithContext(_ aws.Context, _ *dynamodb.ListTagsOfResourceInput, _ ...request.Option) (*dynamodb.ListTagsOfResourceOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) ListTagsOfResourceWithContext(_ aws.Context, in *dynamodb.ListTagsOfResourceInput, _ ...request.Option) (*dynamodb.ListTagsOfResourceOutput, error) {
+	return nil, u.record("ListTagsOfResource", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ListTagsOfResourceRequest(_ *dynamodb.ListTagsOfResourceInput) (*request.Request, *dynamodb.ListTagsOfResourceOutput) {
+func (u *UnimplementedDB) ListTagsOfResourceRequest(in *dynamodb.ListTagsOfResourceInput) (*request.Request, *dynamodb.ListTagsOfResourceOutput) {
+	u.record("ListTagsOfResource", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) PutItem(_ *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) PutItem(in *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	return nil, u.record("PutItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) PutItemWithContext(_ aws.Context, _ *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) PutItemWithContext(_ aws.Context, in *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	return nil, u.record("PutItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) PutItemRequest(_ *dynamodb.PutItemInput) (*request.Request, *dynamodb.PutItemOutput) {
+func (u *UnimplementedDB) PutItemRequest(in *dynamodb.PutItemInput) (*request.Request, *dynamodb.PutItemOutput) {
+	u.record("PutItem", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) Query(_ *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) Query(in *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	return nil, u.record("Query", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) QueryWithContext(_ aws.Context, _ *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) QueryWithContext(_ aws.Context, in *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
+	return nil, u.record("Query", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) QueryRequest(_ *dynamodb.QueryInput) (*request.Request, *dynamodb.QueryOutput) {
+func (u *UnimplementedDB) QueryRequest(in *dynamodb.QueryInput) (*request.Request, *dynamodb.QueryOutput) {
+	u.record("Query", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) QueryPages(_ *dynamodb.QueryInput, _ func(*dynamodb.QueryOutput, bool) bool) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) QueryPages(in *dynamodb.QueryInput, _ func(*dynamodb.QueryOutput, bool) bool) error {
+	return u.record("Query", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) QueryPagesWithContext(_ aws.Context, _ *dynamodb.QueryInput, _ func(*dynamodb.QueryOutput, bool) bool, _ ...request.Option) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) QueryPagesWithContext(_ aws.Context, in *dynamodb.QueryInput, _ func(*dynamodb.QueryOutput, bool) bool, _ ...request.Option) error {
+	return u.record("Query", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) RestoreTableFromBackup(_ *dynamodb.RestoreTableFromBackupInput) (*dynamodb.RestoreTableFromBackupOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) RestoreTableFromBackup(in *dynamodb.RestoreTableFromBackupInput) (*dynamodb.RestoreTableFromBackupOutput, error) {
+	return nil, u.record("RestoreTableFromBackup", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) RestoreTableFromBackupWithContext(_ aws.Context, _ *dynamodb.RestoreTableFromBackupInput, _ ...request.Option) (*dynamodb.RestoreTableFromBackupOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) RestoreTableFromBackupWithContext(_ aws.Context, in *dynamodb.RestoreTableFromBackupInput, _ ...request.Option) (*dynamodb.RestoreTableFromBackupOutput, error) {
+	return nil, u.record("RestoreTableFromBackup", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) RestoreTableFromBackupRequest(_ *dynamodb.RestoreTableFromBackupInput) (*request.Request, *dynamodb.RestoreTableFromBackupOutput) {
+func (u *UnimplementedDB) RestoreTableFromBackupRequest(in *dynamodb.RestoreTableFromBackupInput) (*request.Request, *dynamodb.RestoreTableFromBackupOutput) {
+	u.record("RestoreTableFromBackup", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) RestoreTableToPointInTime(_ *dynamodb.RestoreTableToPointInTimeInput) (*dynamodb.RestoreTableToPointInTimeOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) RestoreTableToPointInTime(in *dynamodb.RestoreTableToPointInTimeInput) (*dynamodb.RestoreTableToPointInTimeOutput, error) {
+	return nil, u.record("RestoreTableToPointInTime", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) RestoreTableToPointInTimeWithContext(_ aws.Context, _ *dynamodb.RestoreTableToPointInTimeInput, _ ...request.Option) (*dynamodb.RestoreTableToPointInTimeOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) RestoreTableToPointInTimeWithContext(_ aws.Context, in *dynamodb.RestoreTableToPointInTimeInput, _ ...request.Option) (*dynamodb.RestoreTableToPointInTimeOutput, error) {
+	return nil, u.record("RestoreTableToPointInTime", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) RestoreTableToPointInTimeRequest(_ *dynamodb.RestoreTableToPointInTimeInput) (*request.Request, *dynamodb.RestoreTableToPointInTimeOutput) {
+func (u *UnimplementedDB) RestoreTableToPointInTimeRequest(in *dynamodb.RestoreTableToPointInTimeInput) (*request.Request, *dynamodb.RestoreTableToPointInTimeOutput) {
+	u.record("RestoreTableToPointInTime", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) Scan(_ *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) Scan(in *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	return nil, u.record("Scan", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ScanWithContext(_ aws.Context, _ *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) ScanWithContext(_ aws.Context, in *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	return nil, u.record("Scan", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ScanRequest(_ *dynamodb.ScanInput) (*request.Request, *dynamodb.ScanOutput) {
+func (u *UnimplementedDB) ScanRequest(in *dynamodb.ScanInput) (*request.Request, *dynamodb.ScanOutput) {
+	u.record("Scan", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) ScanPages(_ *dynamodb.ScanInput, _ func(*dynamodb.ScanOutput, bool) bool) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) ScanPages(in *dynamodb.ScanInput, _ func(*dynamodb.ScanOutput, bool) bool) error {
+	return u.record("Scan", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) ScanPagesWithContext(_ aws.Context, _ *dynamodb.ScanInput, _ func(*dynamodb.ScanOutput, bool) bool, _ ...request.Option) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) ScanPagesWithContext(_ aws.Context, in *dynamodb.ScanInput, _ func(*dynamodb.ScanOutput, bool) bool, _ ...request.Option) error {
+	return u.record("Scan", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) TagResource(_ *dynamodb.TagResourceInput) (*dynamodb.TagResourceOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) TagResource(in *dynamodb.TagResourceInput) (*dynamodb.TagResourceOutput, error) {
+	return nil, u.record("TagResource", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) TagResourceWithContext(_ aws.Context, _ *dynamodb.TagResourceInput, _ ...request.Option) (*dynamodb.TagResourceOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) TagResourceWithContext(_ aws.Context, in *dynamodb.TagResourceInput, _ ...request.Option) (*dynamodb.TagResourceOutput, error) {
+	return nil, u.record("TagResource", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) TagResourceRequest(_ *dynamodb.TagResourceInput) (*request.Request, *dynamodb.TagResourceOutput) {
+func (u *UnimplementedDB) TagResourceRequest(in *dynamodb.TagResourceInput) (*request.Request, *dynamodb.TagResourceOutput) {
+	u.record("TagResource", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) TransactGetItems(_ *dynamodb.TransactGetItemsInput) (*dynamodb.TransactGetItemsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) TransactGetItems(in *dynamodb.TransactGetItemsInput) (*dynamodb.TransactGetItemsOutput, error) {
+	return nil, u.record("TransactGetItems", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) TransactGetItemsWithContext(_ aws.Context, _ *dynamodb.TransactGetItemsInput, _ ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) TransactGetItemsWithContext(_ aws.Context, in *dynamodb.TransactGetItemsInput, _ ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
+	return nil, u.record("TransactGetItems", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) TransactGetItemsRequest(_ *dynamodb.TransactGetItemsInput) (*request.Request, *dynamodb.TransactGetItemsOutput) {
+func (u *UnimplementedDB) TransactGetItemsRequest(in *dynamodb.TransactGetItemsInput) (*request.Request, *dynamodb.TransactGetItemsOutput) {
+	u.record("TransactGetItems", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) TransactWriteItems(_ *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) TransactWriteItems(in *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	return nil, u.record("TransactWriteItems", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) TransactWriteItemsWithContext(_ aws.Context, _ *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) TransactWriteItemsWithContext(_ aws.Context, in *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	return nil, u.record("TransactWriteItems", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) TransactWriteItemsRequest(_ *dynamodb.TransactWriteItemsInput) (*request.Request, *dynamodb.TransactWriteItemsOutput) {
+func (u *UnimplementedDB) TransactWriteItemsRequest(in *dynamodb.TransactWriteItemsInput) (*request.Request, *dynamodb.TransactWriteItemsOutput) {
+	u.record("TransactWriteItems", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) UntagResource(_ *dynamodb.UntagResourceInput) (*dynamodb.UntagResourceOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UntagResource(in *dynamodb.UntagResourceInput) (*dynamodb.UntagResourceOutput, error) {
+	return nil, u.record("UntagResource", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UntagResourceWithContext(_ aws.Context, _ *dynamodb.UntagResourceInput, _ ...request.Option) (*dynamodb.UntagResourceOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UntagResourceWithContext(_ aws.Context, in *dynamodb.UntagResourceInput, _ ...request.Option) (*dynamodb.UntagResourceOutput, error) {
+	return nil, u.record("UntagResource", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UntagResourceRequest(_ *dynamodb.UntagResourceInput) (*request.Request, *dynamodb.UntagResourceOutput) {
+func (u *UnimplementedDB) UntagResourceRequest(in *dynamodb.UntagResourceInput) (*request.Request, *dynamodb.UntagResourceOutput) {
+	u.record("UntagResource", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) UpdateContinuousBackups(_ *dynamodb.UpdateContinuousBackupsInput) (*dynamodb.UpdateContinuousBackupsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateContinuousBackups(in *dynamodb.UpdateContinuousBackupsInput) (*dynamodb.UpdateContinuousBackupsOutput, error) {
+	return nil, u.record("UpdateContinuousBackups", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateContinuousBackupsWithContext(_ aws.Context, _ *dynamodb.UpdateContinuousBackupsInput, _ ...request.Option) (*dynamodb.UpdateContinuousBackupsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateContinuousBackupsWithContext(_ aws.Context, in *dynamodb.UpdateContinuousBackupsInput, _ ...request.Option) (*dynamodb.UpdateContinuousBackupsOutput, error) {
+	return nil, u.record("UpdateContinuousBackups", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateContinuousBackupsRequest(_ *dynamodb.UpdateContinuousBackupsInput) (*request.Request, *dynamodb.UpdateContinuousBackupsOutput) {
+func (u *UnimplementedDB) UpdateContinuousBackupsRequest(in *dynamodb.UpdateContinuousBackupsInput) (*request.Request, *dynamodb.UpdateContinuousBackupsOutput) {
+	u.record("UpdateContinuousBackups", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) UpdateContributorInsights(_ *dynamodb.UpdateContributorInsightsInput) (*dynamodb.UpdateContributorInsightsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateContributorInsights(in *dynamodb.UpdateContributorInsightsInput) (*dynamodb.UpdateContributorInsightsOutput, error) {
+	return nil, u.record("UpdateContributorInsights", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateContributorInsightsWithContext(_ aws.Context, _ *dynamodb.UpdateContributorInsightsInput, _ ...request.Option) (*dynamodb.UpdateContributorInsightsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateContributorInsightsWithContext(_ aws.Context, in *dynamodb.UpdateContributorInsightsInput, _ ...request.Option) (*dynamodb.UpdateContributorInsightsOutput, error) {
+	return nil, u.record("UpdateContributorInsights", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateContributorInsightsRequest(_ *dynamodb.UpdateContributorInsightsInput) (*request.Request, *dynamodb.UpdateContributorInsightsOutput) {
+func (u *UnimplementedDB) UpdateContributorInsightsRequest(in *dynamodb.UpdateContributorInsightsInput) (*request.Request, *dynamodb.UpdateContributorInsightsOutput) {
+	u.record("UpdateContributorInsights", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) UpdateGlobalTable(_ *dynamodb.UpdateGlobalTableInput) (*dynamodb.UpdateGlobalTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateGlobalTable(in *dynamodb.UpdateGlobalTableInput) (*dynamodb.UpdateGlobalTableOutput, error) {
+	return nil, u.record("UpdateGlobalTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateGlobalTableWithContext(_ aws.Context, _ *dynamodb.UpdateGlobalTableInput, _ ...request.Option) (*dynamodb.UpdateGlobalTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateGlobalTableWithContext(_ aws.Context, in *dynamodb.UpdateGlobalTableInput, _ ...request.Option) (*dynamodb.UpdateGlobalTableOutput, error) {
+	return nil, u.record("UpdateGlobalTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateGlobalTableRequest(_ *dynamodb.UpdateGlobalTableInput) (*request.Request, *dynamodb.UpdateGlobalTableOutput) {
+func (u *UnimplementedDB) UpdateGlobalTableRequest(in *dynamodb.UpdateGlobalTableInput) (*request.Request, *dynamodb.UpdateGlobalTableOutput) {
+	u.record("UpdateGlobalTable", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) UpdateGlobalTableSettings(_ *dynamodb.UpdateGlobalTableSettingsInput) (*dynamodb.UpdateGlobalTableSettingsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateGlobalTableSettings(in *dynamodb.UpdateGlobalTableSettingsInput) (*dynamodb.UpdateGlobalTableSettingsOutput, error) {
+	return nil, u.record("UpdateGlobalTableSettings", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateGlobalTableSettingsWithContext(_ aws.Context, _ *dynamodb.UpdateGlobalTableSettingsInput, _ ...request.Option) (*dynamodb.UpdateGlobalTableSettingsOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateGlobalTableSettingsWithContext(_ aws.Context, in *dynamodb.UpdateGlobalTableSettingsInput, _ ...request.Option) (*dynamodb.UpdateGlobalTableSettingsOutput, error) {
+	return nil, u.record("UpdateGlobalTableSettings", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateGlobalTableSettingsRequest(_ *dynamodb.UpdateGlobalTableSettingsInput) (*request.Request, *dynamodb.UpdateGlobalTableSettingsOutput) {
+func (u *UnimplementedDB) UpdateGlobalTableSettingsRequest(in *dynamodb.UpdateGlobalTableSettingsInput) (*request.Request, *dynamodb.UpdateGlobalTableSettingsOutput) {
+	u.record("UpdateGlobalTableSettings", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) UpdateItem(_ *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateItem(in *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	return nil, u.record("UpdateItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateItemWithContext(_ aws.Context, _ *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateItemWithContext(_ aws.Context, in *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	return nil, u.record("UpdateItem", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateItemRequest(_ *dynamodb.UpdateItemInput) (*request.Request, *dynamodb.UpdateItemOutput) {
+func (u *UnimplementedDB) UpdateItemRequest(in *dynamodb.UpdateItemInput) (*request.Request, *dynamodb.UpdateItemOutput) {
+	u.record("UpdateItem", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) UpdateTable(_ *dynamodb.UpdateTableInput) (*dynamodb.UpdateTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateTable(in *dynamodb.UpdateTableInput) (*dynamodb.UpdateTableOutput, error) {
+	return nil, u.record("UpdateTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateTableWithContext(_ aws.Context, _ *dynamodb.UpdateTableInput, _ ...request.Option) (*dynamodb.UpdateTableOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateTableWithContext(_ aws.Context, in *dynamodb.UpdateTableInput, _ ...request.Option) (*dynamodb.UpdateTableOutput, error) {
+	return nil, u.record("UpdateTable", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateTableRequest(_ *dynamodb.UpdateTableInput) (*request.Request, *dynamodb.UpdateTableOutput) {
+func (u *UnimplementedDB) UpdateTableRequest(in *dynamodb.UpdateTableInput) (*request.Request, *dynamodb.UpdateTableOutput) {
+	u.record("UpdateTable", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) UpdateTableReplicaAutoScaling(_ *dynamodb.UpdateTableReplicaAutoScalingInput) (*dynamodb.UpdateTableReplicaAutoScalingOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateTableReplicaAutoScaling(in *dynamodb.UpdateTableReplicaAutoScalingInput) (*dynamodb.UpdateTableReplicaAutoScalingOutput, error) {
+	return nil, u.record("UpdateTableReplicaAutoScaling", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateTableReplicaAutoScalingWithContext(_ aws.Context, _ *dynamodb.UpdateTableReplicaAutoScalingInput, _ ...request.Option) (*dynamodb.UpdateTableReplicaAutoScalingOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateTableReplicaAutoScalingWithContext(_ aws.Context, in *dynamodb.UpdateTableReplicaAutoScalingInput, _ ...request.Option) (*dynamodb.UpdateTableReplicaAutoScalingOutput, error) {
+	return nil, u.record("UpdateTableReplicaAutoScaling", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateTableReplicaAutoScalingRequest(_ *dynamodb.UpdateTableReplicaAutoScalingInput) (*request.Request, *dynamodb.UpdateTableReplicaAutoScalingOutput) {
+func (u *UnimplementedDB) UpdateTableReplicaAutoScalingRequest(in *dynamodb.UpdateTableReplicaAutoScalingInput) (*request.Request, *dynamodb.UpdateTableReplicaAutoScalingOutput) {
+	u.record("UpdateTableReplicaAutoScaling", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) UpdateTimeToLive(_ *dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateTimeToLive(in *dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	return nil, u.record("UpdateTimeToLive", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateTimeToLiveWithContext(_ aws.Context, _ *dynamodb.UpdateTimeToLiveInput, _ ...request.Option) (*dynamodb.UpdateTimeToLiveOutput, error) {
-	return nil, ErrUnimpl
+func (u *UnimplementedDB) UpdateTimeToLiveWithContext(_ aws.Context, in *dynamodb.UpdateTimeToLiveInput, _ ...request.Option) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	return nil, u.record("UpdateTimeToLive", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) UpdateTimeToLiveRequest(_ *dynamodb.UpdateTimeToLiveInput) (*request.Request, *dynamodb.UpdateTimeToLiveOutput) {
+func (u *UnimplementedDB) UpdateTimeToLiveRequest(in *dynamodb.UpdateTimeToLiveInput) (*request.Request, *dynamodb.UpdateTimeToLiveOutput) {
+	u.record("UpdateTimeToLive", in, nil, nil, 0)
 	return nil, nil
 }
 
-func (*UnimplementedDB) WaitUntilTableExists(_ *dynamodb.DescribeTableInput) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) WaitUntilTableExists(in *dynamodb.DescribeTableInput) error {
+	return u.record("WaitUntilTableExists", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) WaitUntilTableExistsWithContext(_ aws.Context, _ *dynamodb.DescribeTableInput, _ ...request.WaiterOption) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) WaitUntilTableExistsWithContext(_ aws.Context, in *dynamodb.DescribeTableInput, _ ...request.WaiterOption) error {
+	return u.record("WaitUntilTableExists", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) WaitUntilTableNotExists(_ *dynamodb.DescribeTableInput) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) WaitUntilTableNotExists(in *dynamodb.DescribeTableInput) error {
+	return u.record("WaitUntilTableNotExists", in, nil, ErrUnimpl, 0)
 }
 
-func (*UnimplementedDB) WaitUntilTableNotExistsWithContext(_ aws.Context, _ *dynamodb.DescribeTableInput, _ ...request.WaiterOption) error {
-	return ErrUnimpl
+func (u *UnimplementedDB) WaitUntilTableNotExistsWithContext(_ aws.Context, in *dynamodb.DescribeTableInput, _ ...request.WaiterOption) error {
+	return u.record("WaitUntilTableNotExists", in, nil, ErrUnimpl, 0)
 }
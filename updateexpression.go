@@ -11,19 +11,23 @@ var ErrInvalidUpdateExpression = errs.Errorf("invalid update expression")
 type updateExpr struct {
 	setExpr          Item
 	removeAttributes []string
+	addExpr          Item
+	deleteExpr       Item
 }
 
-// Limited to SET a=:value1, b=:value2 .... REMOVE c, d, e // at this stage
+// Limited to SET a=:value1, b=:value2 .... REMOVE c, d, e ... ADD f :value3 ... DELETE g :value4 // at this stage
 func parseUpdateExpr(str *string, valueSub Item, nameSub map[string]*string) (*updateExpr, error) {
 	if str == nil {
 		return nil, errs.Errorf("%v: %v", ErrNil, ErrInvalidUpdateExpression)
 	}
 	s := strings.TrimSpace(*str)
-	if !hasPrefixWords(s, []string{"SET ", "REMOVE "}) {
+	if !hasPrefixWords(s, []string{"SET ", "REMOVE ", "ADD ", "DELETE "}) {
 		return nil, errs.Errorf("%v: bad prefix '%s'", ErrInvalidUpdateExpression, s)
 	}
-	expr := trimSpaceWords(splitWords(s, []string{" SET ", " REMOVE "}))
+	expr := trimSpaceWords(splitWords(s, []string{" SET ", " REMOVE ", " ADD ", " DELETE "}))
 	setExpr := Item{}
+	addExpr := Item{}
+	deleteExpr := Item{}
 	var removeAttr []string
 	for _, e := range expr {
 		if strings.HasPrefix(e, "SET ") {
@@ -37,9 +41,26 @@ func parseUpdateExpr(str *string, valueSub Item, nameSub map[string]*string) (*u
 			if removeAttr, err = addRemoveAttr(e, removeAttr, nameSub); err != nil {
 				return nil, err
 			}
+		} else if strings.HasPrefix(e, "ADD ") {
+			e = strings.TrimSpace(strings.TrimPrefix(e, "ADD "))
+			if err := addActionExpr(e, addExpr, valueSub, nameSub); err != nil {
+				return nil, err
+			}
+		} else if strings.HasPrefix(e, "DELETE ") {
+			e = strings.TrimSpace(strings.TrimPrefix(e, "DELETE "))
+			if err := addActionExpr(e, deleteExpr, valueSub, nameSub); err != nil {
+				return nil, err
+			}
 		}
 	}
-	return &updateExpr{setExpr: setExpr, removeAttributes: removeAttr}, nil
+	return &updateExpr{setExpr: setExpr, removeAttributes: removeAttr, addExpr: addExpr, deleteExpr: deleteExpr}, nil
+}
+
+func (u *updateExpr) updatesAttr(name string) bool {
+	_, set := u.setExpr[name]
+	_, add := u.addExpr[name]
+	_, del := u.deleteExpr[name]
+	return set || add || del || containsStr(u.removeAttributes, name)
 }
 
 func addSetExpr(es string, setExpr Item, valueSub Item, nameSub map[string]*string) error {
@@ -70,6 +91,72 @@ func addSetExpr(es string, setExpr Item, valueSub Item, nameSub map[string]*stri
 	return nil
 }
 
+// apply returns a copy of old, or of key if old is nil, with all update
+// actions applied.
+func (u *updateExpr) apply(key, old Item) (Item, error) {
+	item := Item{}
+	for k, v := range key {
+		item[k] = v
+	}
+	for k, v := range old {
+		item[k] = v
+	}
+	for k, v := range u.setExpr {
+		item[k] = v
+	}
+	for _, k := range u.removeAttributes {
+		delete(item, k)
+	}
+	for k, v := range u.addExpr {
+		av, err := addAttr(item[k], v)
+		if err != nil {
+			return nil, err
+		}
+		item[k] = av
+	}
+	for k, v := range u.deleteExpr {
+		av, err := deleteAttr(item[k], v)
+		if err != nil {
+			return nil, err
+		}
+		if av == nil {
+			delete(item, k)
+		} else {
+			item[k] = av
+		}
+	}
+	return item, nil
+}
+
+// addActionExpr parses the arguments of ADD and DELETE actions:
+// a :value1, b :value2 ...
+func addActionExpr(es string, actionExpr Item, valueSub Item, nameSub map[string]*string) error {
+	pairs := strings.Split(es, ",")
+	for _, pair := range pairs {
+		p := strings.Fields(pair)
+		if len(p) != 2 {
+			return errs.Errorf("%v: expected attribute name and value in '%s'", ErrInvalidUpdateExpression, pair)
+		}
+		name, val := p[0], p[1]
+		if !reExprName.MatchString(name) {
+			return errs.Errorf("%v: invalid expression attribute name '%s' ", ErrInvalidUpdateExpression, name)
+		}
+		if !reExprVal.MatchString(val) {
+			return errs.Errorf("%v: invalid expression attribute value '%s' ", ErrInvalidUpdateExpression, val)
+		}
+		name2, err := substituteName(name, nameSub)
+		if err != nil {
+			return err
+		}
+		av, ok := valueSub[val]
+		if !ok {
+			return errs.Errorf("%v: %s", ErrSubstitution, val)
+		}
+		actionExpr[name2] = av
+	}
+	return nil
+}
+
 func addRemoveAttr(es string, removeAttr []string, nameSub map[string]*string) ([]string, error) {
 	attrs := strings.Split(es, ",")
 	for _, attr := range attrs {
@@ -84,3 +84,48 @@ func TestParseUpdateExprErr(t *testing.T) {
 	_, err = parseUpdateExpr(strPtr("SET name=:name"), nil, nil)
 	requireErrIs(t, err, ErrSubstitution)
 }
+
+func TestParseUpdateExprAddDelete(t *testing.T) {
+	valueSub := Item{
+		":n":    {N: strPtr("1")},
+		":tags": strSet("a"),
+		":old":  strSet("b"),
+	}
+	nameSub := map[string]*string{"#t": strPtr("tags")}
+	s := strPtr("SET x=:n ADD count :n, #t :tags DELETE old :old REMOVE y")
+	got, err := parseUpdateExpr(s, valueSub, nameSub)
+	require.NoError(t, err)
+	want := &updateExpr{
+		setExpr:          Item{"x": valueSub[":n"]},
+		removeAttributes: []string{"y"},
+		addExpr:          Item{"count": valueSub[":n"], "tags": valueSub[":tags"]},
+		deleteExpr:       Item{"old": valueSub[":old"]},
+	}
+	require.Equal(t, want, got)
+	require.True(t, got.updatesAttr("tags"))
+	require.True(t, got.updatesAttr("old"))
+	require.True(t, got.updatesAttr("y"))
+	require.False(t, got.updatesAttr("z"))
+}
+
+func TestParseUpdateExprAddDeleteErr(t *testing.T) {
+	valueSub := Item{":n": {N: strPtr("1")}}
+	testCases := map[string]error{
+		"ADD a":            ErrInvalidUpdateExpression,
+		"ADD a :n :n":      ErrInvalidUpdateExpression,
+		"ADD //a :n":       ErrInvalidUpdateExpression,
+		"ADD a n":          ErrInvalidUpdateExpression,
+		"ADD #a :n":        ErrSubstitution,
+		"ADD a :missing":   ErrSubstitution,
+		"DELETE a :n, b":   ErrInvalidUpdateExpression,
+		"DELETE a :x":      ErrSubstitution,
+		"SET a=:n DELETE ": ErrInvalidUpdateExpression,
+	}
+	for s, want := range testCases {
+		s, want := s, want
+		t.Run(s, func(t *testing.T) {
+			_, err := parseUpdateExpr(&s, valueSub, nil)
+			requireErrIs(t, err, want)
+		})
+	}
+}
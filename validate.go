@@ -3,6 +3,7 @@ package dynamock
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"foxygo.at/s/errs"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -23,6 +24,16 @@ var (
 	ErrMissingType      = errors.New("missing type")
 	ErrInvalidType      = errors.New("invalid type")
 	ErrMissingAttribute = errors.New("missing attribute")
+	ErrInvalidAttr      = errors.New("invalid attribute value")
+	ErrEmptyKeyValue    = errors.New("empty key value")
+	ErrEmptySet         = errors.New("empty set")
+	ErrNestingDepth     = errors.New("nesting levels have exceeded supported limits")
+	ErrItemSize         = errors.New("item size has exceeded the maximum allowed size")
+)
+
+const (
+	maxItemSize     = 400 * 1024
+	maxNestingDepth = 32
 )
 
 func validateTable(t *Table) error {
@@ -32,6 +43,7 @@ func validateTable(t *Table) error {
 	if err := validateKeyDef(t.schema.PrimaryKey); err != nil {
 		return errs.Errorf("%v: %v (primary key)", ErrSchemaValidation, err)
 	}
+	names := map[string]bool{}
 	for _, gsi := range t.schema.GSIs {
 		if gsi.Name == "" {
 			return errs.Errorf("validateTable: %v: globalSecondaryIndex.Name in table %s", ErrMissingName, t.name)
@@ -39,6 +51,22 @@ func validateTable(t *Table) error {
 		if err := validateKeyDef(gsi); err != nil {
 			return errs.Errorf("%v: %v (globalSecondaryIndex %s)", ErrSchemaValidation, err, gsi.Name)
 		}
+		if names[gsi.Name] {
+			return errs.Errorf("%v: %v: index name %s", ErrSchemaValidation, ErrDuplicate, gsi.Name)
+		}
+		names[gsi.Name] = true
+	}
+	for _, lsi := range t.schema.LSIs {
+		if lsi.Name == "" {
+			return errs.Errorf("validateTable: %v: localSecondaryIndex.Name in table %s", ErrMissingName, t.name)
+		}
+		if err := validateLSI(lsi, t.schema.PrimaryKey); err != nil {
+			return errs.Errorf("%v: %v (localSecondaryIndex %s)", ErrSchemaValidation, err, lsi.Name)
+		}
+		if names[lsi.Name] {
+			return errs.Errorf("%v: %v: index name %s", ErrSchemaValidation, ErrDuplicate, lsi.Name)
+		}
+		names[lsi.Name] = true
 	}
 	for _, item := range t.items {
 		if err := validateItem(item, t.schema); err != nil {
@@ -48,6 +76,21 @@ func validateTable(t *Table) error {
 	return nil
 }
 
+// validateLSI checks that lsi has a sort key and the partition key of
+// the primary key.
+func validateLSI(lsi KeyDef, pk KeyDef) error {
+	if err := validateKeyDef(lsi); err != nil {
+		return err
+	}
+	if lsi.SortKey == nil {
+		return errs.Errorf("%v: sortKey", ErrMissingName)
+	}
+	if lsi.PartitionKey != pk.PartitionKey {
+		return errs.Errorf("%v: partitionKey must match primary key partitionKey '%s'", ErrInvalidKey, pk.PartitionKey.Name)
+	}
+	return nil
+}
+
 func validateKeyDef(k KeyDef) error {
 	if err := validateKeyPartDef(&k.PartitionKey); err != nil {
 		return err
@@ -69,7 +112,7 @@ func validateItem(item Item, schema Schema) error {
 	if err := validateKey(item, schema.PrimaryKey); err != nil {
 		return errs.New(ErrPrimaryKeyVal, err)
 	}
-	for _, gsi := range schema.GSIs {
+	for _, gsi := range schema.secondaryIndexes() {
 		if !hasKey(item, gsi) {
 			continue
 		}
@@ -77,9 +120,162 @@ func validateItem(item Item, schema Schema) error {
 			return errs.New(ErrGSIVal, err)
 		}
 	}
+	for _, name := range sortedNames(item) {
+		if err := validateAttr(item[name], 0); err != nil {
+			return errs.Errorf("%v: attribute '%s': %v", ErrItemValidation, name, err)
+		}
+	}
+	if size := ItemSize(item); size > maxItemSize {
+		return errs.Errorf("%v: %v: %d bytes", ErrItemValidation, ErrItemSize, size)
+	}
+	return nil
+}
+
+// validateAttr checks that attr holds exactly one valid data type.
+// depth is the number of lists and maps attr is nested in.
+func validateAttr(attr *dynamodb.AttributeValue, depth int) error {
+	if n := countTypes(attr); n != 1 {
+		return errs.Errorf("%v: %d data types set, must contain exactly one", ErrInvalidAttr, n)
+	}
+	switch {
+	case attr.NULL != nil && !*attr.NULL:
+		return errs.Errorf("%v: NULL must be true", ErrInvalidAttr)
+	case attr.N != nil:
+		_, err := ParseNumber(*attr.N)
+		return err
+	case attr.SS != nil:
+		return validateSet(attr.SS, "string", func(s *string) (string, error) { return *s, nil })
+	case attr.NS != nil:
+		return validateSet(attr.NS, "number", func(s *string) (string, error) {
+			n, err := ParseNumber(*s)
+			return n.String(), err
+		})
+	case attr.BS != nil:
+		ss := make([]*string, len(attr.BS))
+		for i, b := range attr.BS {
+			if b != nil {
+				s := string(b)
+				ss[i] = &s
+			}
+		}
+		return validateSet(ss, "binary", func(s *string) (string, error) { return *s, nil })
+	case attr.L != nil || attr.M != nil:
+		if depth+1 > maxNestingDepth {
+			return errs.Errorf("%v: more than %d levels", ErrNestingDepth, maxNestingDepth)
+		}
+		for _, a := range attr.L {
+			if err := validateAttr(a, depth+1); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedNames(attr.M) {
+			if err := validateAttr(attr.M[name], depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func countTypes(attr *dynamodb.AttributeValue) int {
+	if attr == nil {
+		return 0
+	}
+	set := []bool{
+		attr.S != nil, attr.N != nil, attr.B != nil, attr.BOOL != nil, attr.NULL != nil,
+		attr.SS != nil, attr.NS != nil, attr.BS != nil, attr.L != nil, attr.M != nil,
+	}
+	n := 0
+	for _, isSet := range set {
+		if isSet {
+			n++
+		}
+	}
+	return n
+}
+
+// validateSet checks that a set is not empty and has no duplicates.
+// canonical returns the value set members are compared by.
+func validateSet(members []*string, typeName string, canonical func(*string) (string, error)) error {
+	if len(members) == 0 {
+		return errs.Errorf("%v: %s set", ErrEmptySet, typeName)
+	}
+	seen := map[string]bool{}
+	for _, m := range members {
+		if m == nil {
+			return errs.Errorf("%v: nil %s set member", ErrInvalidAttr, typeName)
+		}
+		c, err := canonical(m)
+		if err != nil {
+			return err
+		}
+		if seen[c] {
+			return errs.Errorf("%v: %v: %s set member '%s'", ErrDuplicate, ErrInvalidAttr, typeName, *m)
+		}
+		seen[c] = true
+	}
 	return nil
 }
 
+func sortedNames(item Item) []string {
+	names := make([]string, 0, len(item))
+	for name := range item {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ItemSize returns the size of an item in bytes as calculated by DynamoDB
+// for the item size limit of 400KB.
+func ItemSize(item Item) int {
+	size := 0
+	for name, attr := range item {
+		size += len(name) + attrSize(attr)
+	}
+	return size
+}
+
+func attrSize(attr *dynamodb.AttributeValue) int {
+	if attr == nil {
+		return 0
+	}
+	size := 0
+	switch {
+	case attr.S != nil:
+		size = len(*attr.S)
+	case attr.N != nil:
+		size = numberSize(*attr.N)
+	case attr.B != nil:
+		size = len(attr.B)
+	case attr.BOOL != nil || attr.NULL != nil:
+		size = 1
+	case attr.L != nil || attr.M != nil:
+		// 3 bytes for the list or map plus 1 byte per element
+		size = 3 + len(attr.L) + len(attr.M)
+		for _, a := range attr.L {
+			size += attrSize(a)
+		}
+		size += ItemSize(attr.M)
+	}
+	for _, s := range attr.SS {
+		size += len(*s)
+	}
+	for _, n := range attr.NS {
+		size += numberSize(*n)
+	}
+	for _, b := range attr.BS {
+		size += len(b)
+	}
+	return size
+}
+
+// numberSize is 1 byte per two significant digits plus 1 byte.
+func numberSize(s string) int {
+	n, _ := ParseNumber(s)
+	return (len(n.digits)+1)/2 + 1
+}
+
 func hasKey(item Item, k KeyDef) bool {
 	pk := k.PartitionKey
 	if item[pk.Name] == nil {
@@ -106,7 +302,7 @@ func validateKey(item Item, k KeyDef) error {
 
 func validateKeyType(typeStr string) error {
 	switch typeStr {
-	case "string", "number":
+	case "string", "number", "binary":
 		return nil
 	}
 	return errs.Errorf("%V: validateKeyType: %s", ErrUnknownType, typeStr)
@@ -118,9 +314,17 @@ func validateAttrKeyType(attr *dynamodb.AttributeValue, attrType string) error {
 	}
 	switch {
 	case attrType == "string" && attr.S != nil:
+		if *attr.S == "" {
+			return errs.Errorf("validateAttrKeyType: %v: string", ErrEmptyKeyValue)
+		}
 		return nil
 	case attrType == "number" && attr.N != nil:
 		return nil
+	case attrType == "binary" && attr.B != nil:
+		if len(attr.B) == 0 {
+			return errs.Errorf("validateAttrKeyType: %v: binary", ErrEmptyKeyValue)
+		}
+		return nil
 	}
 	return errs.Errorf("%v: no %s in attribute %+v", ErrMissingType, attrType, attr)
 }
@@ -2,6 +2,7 @@ package dynamock
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -69,6 +70,12 @@ func TestValidateAttrKeyTypeErr(t *testing.T) {
 	err := validateAttrKeyType(nil, "string")
 	requireErrIs(t, err, ErrMissingAttribute)
 
+	err = validateAttrKeyType(&dynamodb.AttributeValue{B: []byte{0x01}}, "binary")
+	require.NoError(t, err)
+
+	err = validateAttrKeyType(&dynamodb.AttributeValue{S: strPtr("AQ==")}, "binary")
+	requireErrIs(t, err, ErrMissingType)
+
 	err = validateAttrKeyType(&dynamodb.AttributeValue{}, "bad_type")
 	requireErrIs(t, err, ErrMissingType)
 }
@@ -116,3 +123,160 @@ func TestValidateIndexName(t *testing.T) {
 	err := validateIndexName(tbl, strPtr("missing_index"))
 	requireErrIs(t, err, ErrUnknownIndex)
 }
+
+func nested(depth int) *dynamodb.AttributeValue {
+	av := &dynamodb.AttributeValue{S: strPtr("leaf")}
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			av = &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{av}}
+		} else {
+			av = &dynamodb.AttributeValue{M: Item{"m": av}}
+		}
+	}
+	return av
+}
+
+func TestValidateAttr(t *testing.T) {
+	valid := []*dynamodb.AttributeValue{
+		{S: strPtr("")},
+		{N: strPtr("1")},
+		{B: []byte{}},
+		{BOOL: new(bool)},
+		{NULL: boolPtr(true)},
+		{SS: []*string{strPtr("a"), strPtr("b")}},
+		{NS: []*string{strPtr("1"), strPtr("1.5")}},
+		{BS: [][]byte{{0x01}, {0x02}}},
+		{L: []*dynamodb.AttributeValue{}},
+		{M: Item{}},
+		nested(32),
+	}
+	for _, av := range valid {
+		require.NoError(t, validateAttr(av, 0), av)
+	}
+
+	err := validateAttr(nil, 0)
+	requireErrIs(t, err, ErrInvalidAttr)
+	err = validateAttr(&dynamodb.AttributeValue{}, 0)
+	requireErrIs(t, err, ErrInvalidAttr)
+	err = validateAttr(&dynamodb.AttributeValue{S: strPtr("a"), N: strPtr("1")}, 0)
+	requireErrIs(t, err, ErrInvalidAttr)
+	err = validateAttr(&dynamodb.AttributeValue{NULL: new(bool)}, 0)
+	requireErrIs(t, err, ErrInvalidAttr)
+	err = validateAttr(&dynamodb.AttributeValue{N: strPtr("1x")}, 0)
+	requireErrIs(t, err, ErrInvalidNumber)
+
+	err = validateAttr(&dynamodb.AttributeValue{SS: []*string{}}, 0)
+	requireErrIs(t, err, ErrEmptySet)
+	err = validateAttr(&dynamodb.AttributeValue{NS: []*string{}}, 0)
+	requireErrIs(t, err, ErrEmptySet)
+	err = validateAttr(&dynamodb.AttributeValue{BS: [][]byte{}}, 0)
+	requireErrIs(t, err, ErrEmptySet)
+
+	err = validateAttr(&dynamodb.AttributeValue{SS: []*string{strPtr("a"), strPtr("a")}}, 0)
+	requireErrIs(t, err, ErrDuplicate)
+	err = validateAttr(&dynamodb.AttributeValue{NS: []*string{strPtr("1"), strPtr("1.0")}}, 0)
+	requireErrIs(t, err, ErrDuplicate)
+	err = validateAttr(&dynamodb.AttributeValue{BS: [][]byte{{0x01}, {0x01}}}, 0)
+	requireErrIs(t, err, ErrDuplicate)
+	err = validateAttr(&dynamodb.AttributeValue{NS: []*string{strPtr("1"), strPtr("x")}}, 0)
+	requireErrIs(t, err, ErrInvalidNumber)
+	err = validateAttr(&dynamodb.AttributeValue{BS: [][]byte{nil}}, 0)
+	requireErrIs(t, err, ErrInvalidAttr)
+
+	err = validateAttr(nested(33), 0)
+	requireErrIs(t, err, ErrNestingDepth)
+	err = validateAttr(&dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{{}}}, 0)
+	requireErrIs(t, err, ErrInvalidAttr)
+	err = validateAttr(&dynamodb.AttributeValue{M: Item{"a": {N: strPtr("1E200")}}}, 0)
+	requireErrIs(t, err, ErrInvalidNumber)
+}
+
+func TestItemSize(t *testing.T) {
+	item := Item{
+		"id":   {S: strPtr("abc")},                            // 2 + 3
+		"n":    {N: strPtr("123")},                            // 1 + 3
+		"zero": {N: strPtr("0")},                              // 4 + 1
+		"ok":   {BOOL: boolPtr(true)},                         // 2 + 1
+		"b":    {B: []byte{0x01, 0x02}},                       // 1 + 2
+		"ss":   {SS: []*string{strPtr("a"), strPtr("bc")}},    // 2 + 3
+		"ns":   {NS: []*string{strPtr("1"), strPtr("12345")}}, // 2 + 2 + 4
+		"bs":   {BS: [][]byte{{0x01}}},                        // 2 + 1
+		"l": {L: []*dynamodb.AttributeValue{ // 1 + 3 + 2 + 1 + 2
+			{S: strPtr("a")},
+			{N: strPtr("1")},
+		}},
+		"m": {M: Item{"k": {S: strPtr("v")}}}, // 1 + 3 + 1 + 1 + 1
+	}
+	require.Equal(t, 5+4+5+3+3+5+8+3+9+7, ItemSize(item))
+	require.Equal(t, 0, attrSize(nil))
+}
+
+func TestValidateItemErr(t *testing.T) {
+	schema := Schema{PrimaryKey: idDef()}
+	item := Item{"id": {S: strPtr("1")}, "data": {S: strPtr(strings.Repeat("x", 400*1024-7))}}
+	require.NoError(t, validateItem(item, schema))
+	item["data"] = &dynamodb.AttributeValue{S: strPtr(strings.Repeat("x", 400*1024-6))}
+	err := validateItem(item, schema)
+	requireErrIs(t, err, ErrItemValidation)
+	requireErrIs(t, err, ErrItemSize)
+
+	item = Item{"id": {S: strPtr("1")}, "tags": {SS: []*string{}}}
+	err = validateItem(item, schema)
+	requireErrIs(t, err, ErrItemValidation)
+	requireErrIs(t, err, ErrEmptySet)
+
+	item = Item{"id": {S: strPtr("")}}
+	err = validateItem(item, schema)
+	requireErrIs(t, err, ErrPrimaryKeyVal)
+	requireErrIs(t, err, ErrEmptyKeyValue)
+
+	schema = Schema{PrimaryKey: KeyDef{PartitionKey: KeyPartDef{Name: "id", Type: "binary"}}}
+	item = Item{"id": {B: []byte{}}}
+	err = validateItem(item, schema)
+	requireErrIs(t, err, ErrEmptyKeyValue)
+}
+
+func TestValidateTableLSIErr(t *testing.T) {
+	pk := KeyDef{PartitionKey: KeyPartDef{Name: "id", Type: "string"}}
+	sk := &KeyPartDef{Name: "n", Type: "number"}
+	testCases := map[string]struct {
+		schema Schema
+		want   error
+	}{
+		"missing_name": {
+			schema: Schema{PrimaryKey: pk, LSIs: []KeyDef{{PartitionKey: pk.PartitionKey, SortKey: sk}}},
+			want:   ErrMissingName,
+		},
+		"missing_sort_key": {
+			schema: Schema{PrimaryKey: pk, LSIs: []KeyDef{{Name: "l", PartitionKey: pk.PartitionKey}}},
+			want:   ErrMissingName,
+		},
+		"bad_type": {
+			schema: Schema{PrimaryKey: pk, LSIs: []KeyDef{{Name: "l", PartitionKey: pk.PartitionKey, SortKey: &KeyPartDef{Name: "n", Type: "bool"}}}},
+			want:   ErrUnknownType,
+		},
+		"partition_key": {
+			schema: Schema{PrimaryKey: pk, LSIs: []KeyDef{{Name: "l", PartitionKey: KeyPartDef{Name: "x", Type: "string"}, SortKey: sk}}},
+			want:   ErrInvalidKey,
+		},
+		"duplicate_gsi": {
+			schema: Schema{PrimaryKey: pk, GSIs: []KeyDef{{Name: "g", PartitionKey: *sk}, {Name: "g", PartitionKey: *sk}}},
+			want:   ErrDuplicate,
+		},
+		"duplicate_lsi": {
+			schema: Schema{
+				PrimaryKey: pk,
+				GSIs:       []KeyDef{{Name: "i", PartitionKey: *sk}},
+				LSIs:       []KeyDef{{Name: "i", PartitionKey: pk.PartitionKey, SortKey: sk}},
+			},
+			want: ErrDuplicate,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			err := validateTable(&Table{name: "t", schema: tc.schema})
+			requireErrIs(t, err, tc.want)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package dynamock
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"foxygo.at/s/errs"
+)
+
+// walRecord is a line of the write-ahead log. Item holds the item after a
+// put or update, or the primary key of a deleted item, in DynamoDB JSON.
+type walRecord struct {
+	Op    string                 `json:"op"`
+	Table string                 `json:"table"`
+	Item  map[string]interface{} `json:"item"`
+}
+
+const (
+	walPut    = "put"
+	walDelete = "delete"
+)
+
+// OpenDBWithLog reads the fixture file or table directory layout at path,
+// see OpenDB, and replays the write-ahead log path+".wal" over it. Every
+// successful PutItem, DeleteItem and UpdateItem is then appended to the
+// log as a single JSON line before it returns, rather than rewriting the
+// whole fixture.
+//
+// A final log record without trailing newline, left by a crash in the
+// middle of a write, is discarded. After compactEvery records the log is
+// compacted: the current state is written to path, see Persist, and the
+// log is truncated. If compactEvery is 0, the log is only compacted by
+// Compact. Close closes the log.
+//
+// As with Persist, a mutation is not undone if appending it to the log
+// fails; the log lacks the mutation until the item is written again or
+// Compact succeeds.
+func OpenDBWithLog(path string, compactEvery int) (*DB, error) {
+	db, err := newDBFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".wal", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, errs.Errorf("%v: %v", ErrPersist, err)
+	}
+	if err := db.replayLog(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	db.persister = &persister{path: path, log: f, compactEvery: compactEvery}
+	return db, nil
+}
+
+// replayLog applies the records of the log f to db and truncates a final
+// incomplete record so that new records are appended after the last
+// complete one.
+func (db *DB) replayLog(f *os.File) error {
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return errs.Errorf("%v: %v", ErrPersist, err)
+	}
+	offset := 0
+	for line := 1; ; line++ {
+		n := bytes.IndexByte(b[offset:], '\n')
+		if n < 0 {
+			break
+		}
+		if err := db.applyLogRecord(b[offset : offset+n]); err != nil {
+			return errs.Errorf("%v: %v (file: '%s', line: %d)", ErrPersist, err, f.Name(), line)
+		}
+		offset += n + 1
+	}
+	if err := f.Truncate(int64(offset)); err != nil {
+		return errs.Errorf("%v: %v", ErrPersist, err)
+	}
+	return nil
+}
+
+func (db *DB) applyLogRecord(b []byte) error {
+	rec := walRecord{}
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return err
+	}
+	table := db.tables[rec.Table]
+	if table == nil {
+		return errs.Errorf("%v: '%s'", ErrUnknownTable, rec.Table)
+	}
+	item, err := decodeTypedItem(rec.Item)
+	if err != nil {
+		return err
+	}
+	switch rec.Op {
+	case walPut:
+		_, err = table.Put(item)
+	case walDelete:
+		_, err = table.Delete(item)
+	default:
+		err = errs.Errorf("%v: log operation '%s'", ErrUnknownType, rec.Op)
+	}
+	return err
+}
+
+// appendLog appends the current state of the item with the primary key of
+// key in table to the log. Records hold full items rather than update
+// expressions, so replaying a record that is already part of a compacted
+// snapshot is harmless.
+func (db *DB) appendLog(p *persister, table *Table, key Item) error {
+	key = primaryKey(key, table.schema.PrimaryKey)
+	rec := walRecord{Op: walPut, Table: table.name}
+	item, _ := table.Get(key)
+	if item == nil {
+		rec.Op = walDelete
+		item = key
+	}
+	rec.Item = encodeTypedItem(item)
+	b, _ := json.Marshal(rec)
+	if _, err := p.log.Write(append(b, '\n')); err != nil {
+		return errs.Errorf("%v: %v", ErrPersist, err)
+	}
+	p.records++
+	if p.compactEvery > 0 && p.records >= p.compactEvery {
+		return db.compact(p)
+	}
+	return nil
+}
+
+// Compact writes the current state to the fixture of OpenDBWithLog and
+// truncates the write-ahead log. It does nothing for a DB without log.
+func (db *DB) Compact() error {
+	p := db.getPersister()
+	if p == nil || p.log == nil {
+		return nil
+	}
+	p.m.Lock()
+	defer p.m.Unlock()
+	return db.compact(p)
+}
+
+// compact writes the snapshot before truncating the log, so a crash in
+// between only replays records the snapshot already contains.
+func (db *DB) compact(p *persister) error {
+	if err := db.writeTo(p.path); err != nil {
+		return err
+	}
+	if err := p.log.Truncate(0); err != nil {
+		return errs.Errorf("%v: %v", ErrPersist, err)
+	}
+	p.records = 0
+	return nil
+}
+
+// primaryKey returns the primary key attributes of item.
+func primaryKey(item Item, keyDef KeyDef) Item {
+	key := Item{}
+	for _, k := range keyParts(keyDef) {
+		key[k.Name] = item[k.Name]
+	}
+	return key
+}
@@ -0,0 +1,184 @@
+package dynamock
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func requireLogLines(t *testing.T, fname string, want int) {
+	t.Helper()
+	b, err := ioutil.ReadFile(fname + ".wal")
+	require.NoError(t, err)
+	require.Equal(t, want, strings.Count(string(b), "\n"))
+}
+
+func TestOpenDBWithLog(t *testing.T) {
+	dir := copyTestdata(t, "db.json")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.json")
+	snap, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	db, err := OpenDBWithLog(path, 0)
+	require.NoError(t, err)
+	require.NoError(t, putProduct(t, db, "8"))
+	require.NoError(t, putProduct(t, db, "9"))
+	_, err = db.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 strPtr("product"),
+		Key:                       Item{"id": {S: strPtr("9")}},
+		UpdateExpression:          strPtr("SET price = :p"),
+		ExpressionAttributeValues: Item{":p": {N: strPtr("99")}},
+	})
+	require.NoError(t, err)
+	_, err = db.DeleteItem(&dynamodb.DeleteItemInput{TableName: strPtr("product"), Key: Item{"id": {S: strPtr("8")}}})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+	requireLogLines(t, path, 4)
+
+	// the snapshot is untouched until compaction
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, snap, b)
+
+	db, err = OpenDBWithLog(path, 2)
+	require.NoError(t, err)
+	product := db.tables["product"]
+	item, err := product.Get(Item{"id": {S: strPtr("8")}})
+	require.NoError(t, err)
+	require.Nil(t, item)
+	require.Equal(t, "99", *product.byPrimary["9"][""]["price"].N)
+
+	require.NoError(t, putProduct(t, db, "10"))
+	requireLogLines(t, path, 5)
+	require.NoError(t, putProduct(t, db, "11"))
+	requireLogLines(t, path, 0)
+	requireProduct(t, path, "11", true)
+	require.NoError(t, putProduct(t, db, "12"))
+	require.NoError(t, db.Compact())
+	requireLogLines(t, path, 0)
+	requireProduct(t, path, "12", true)
+	require.NoError(t, db.Close())
+
+	require.NoError(t, NewDB().Compact())
+}
+
+func TestOpenDBWithLogDir(t *testing.T) {
+	create := `{"TableName": "product", "KeySchema": [{"AttributeName": "id", "KeyType": "HASH"}],
+		"AttributeDefinitions": [{"AttributeName": "id", "AttributeType": "S"}]}`
+	dir := writeExportFiles(t, map[string]string{
+		"db/t/table.json":  `{"createTable": "create.json"}`,
+		"db/t/create.json": create,
+		"db/t/a.jsonl":     `{"id": "1"}`,
+		"db/r/table.yaml":  "name: person\nschema: {primaryKey: {partitionKey: {name: id, type: number}}}\nitems: [{id: 1}]\n",
+	})
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db")
+	db, err := OpenDBWithLog(path, 0)
+	require.NoError(t, err)
+	require.NoError(t, putProduct(t, db, "2"))
+	_, err = db.PutItem(&dynamodb.PutItemInput{TableName: strPtr("person"), Item: Item{"id": {N: strPtr("2")}}})
+	require.NoError(t, err)
+	require.NoError(t, db.Compact())
+	requireLogLines(t, path, 0)
+	require.NoError(t, db.Close())
+
+	// compaction writes tables back to the files they were read from
+	b, err := ioutil.ReadFile(filepath.Join(path, "t", "create.json"))
+	require.NoError(t, err)
+	require.Equal(t, create, string(b))
+	b, err = ioutil.ReadFile(filepath.Join(path, "t", "a.jsonl"))
+	require.NoError(t, err)
+	require.Equal(t, `{"id":"1"}`+"\n", string(b))
+	fis, err := ioutil.ReadDir(path)
+	require.NoError(t, err)
+	require.Len(t, fis, 2)
+
+	db, err = NewDBFromDir(path)
+	require.NoError(t, err)
+	require.Len(t, db.tables["product"].items, 2)
+	require.Len(t, db.tables["person"].items, 2)
+}
+
+func TestOpenDBWithLogTruncated(t *testing.T) {
+	dir := copyTestdata(t, "db.json")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.json")
+	log := `{"op": "put", "table": "product", "item": {"id": {"S": "8"}}}` + "\n" +
+		`{"op": "put", "table": "product", "item": {"id": {"S": "9"`
+	require.NoError(t, ioutil.WriteFile(path+".wal", []byte(log), 0o600))
+
+	db, err := OpenDBWithLog(path, 0)
+	require.NoError(t, err)
+	require.NotNil(t, db.tables["product"].byPrimary["8"])
+	require.Nil(t, db.tables["product"].byPrimary["9"])
+	requireLogLines(t, path, 1)
+
+	// new records follow the last complete record
+	require.NoError(t, putProduct(t, db, "10"))
+	require.NoError(t, db.Close())
+	db, err = OpenDBWithLog(path, 0)
+	require.NoError(t, err)
+	require.NotNil(t, db.tables["product"].byPrimary["10"])
+	require.NoError(t, db.Close())
+}
+
+func TestOpenDBWithLogErr(t *testing.T) {
+	testCases := map[string]struct {
+		log  string
+		want error
+	}{
+		"bad_json":      {log: "{\n", want: ErrPersist},
+		"unknown_table": {log: `{"op": "put", "table": "MISSING", "item": {}}` + "\n", want: ErrUnknownTable},
+		"bad_item":      {log: `{"op": "put", "table": "product", "item": {"id": "1"}}` + "\n", want: ErrInvalidAttr},
+		"invalid_item":  {log: `{"op": "put", "table": "product", "item": {"x": {"S": "1"}}}` + "\n", want: ErrMissingAttribute},
+		"unknown_op":    {log: `{"op": "get", "table": "product", "item": {"id": {"S": "1"}}}` + "\n", want: ErrUnknownType},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			dir := copyTestdata(t, "db.json")
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "db.json")
+			require.NoError(t, ioutil.WriteFile(path+".wal", []byte(tc.log), 0o600))
+			_, err := OpenDBWithLog(path, 0)
+			requireErrIs(t, err, ErrPersist)
+			requireErrIs(t, err, tc.want)
+		})
+	}
+
+	_, err := OpenDBWithLog(filepath.Join("testdata", "MISSING.json"), 0)
+	require.Error(t, err)
+	dir := copyTestdata(t, "db.json")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.json")
+	require.NoError(t, os.Mkdir(path+".wal", 0o755))
+	_, err = OpenDBWithLog(path, 0)
+	requireErrIs(t, err, ErrPersist)
+}
+
+func TestOpenDBWithLogWriteErr(t *testing.T) {
+	dir := copyTestdata(t, "db.json")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.json")
+	db, err := OpenDBWithLog(path, 1)
+	require.NoError(t, err)
+
+	// the snapshot cannot be written over a directory
+	require.NoError(t, os.Rename(path, path+".orig"))
+	require.NoError(t, os.MkdirAll(filepath.Join(path, "product", "table.json", "x"), 0o755))
+	requireErrIs(t, putProduct(t, db, "8"), ErrPersist)
+
+	require.NoError(t, db.persister.log.Close())
+	requireErrIs(t, putProduct(t, db, "9"), ErrPersist)
+	requireErrIs(t, db.compact(db.persister), ErrPersist)
+	require.NoError(t, os.RemoveAll(path))
+	requireErrIs(t, db.Compact(), ErrPersist)
+	requireErrIs(t, db.Close(), ErrPersist)
+	require.Nil(t, db.persister)
+}
@@ -0,0 +1,109 @@
+package dynamock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Watcher reloads a DB when its fixture changes on disk, see DB.Watch.
+type Watcher struct {
+	db       *DB
+	path     string
+	onReload func(error)
+	stamp    string
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Reload replaces the tables of db with the tables of the fixture file or
+// table directory layout at path, see OpenDB. Every table of the new
+// fixture is validated with its items while loading, and all tables are
+// swapped in a single step once loading succeeded, so concurrent requests
+// see either the old or the new tables. If loading fails, db keeps its
+// tables and the error is returned.
+func (db *DB) Reload(path string) error {
+	db2, err := newDBFromPath(path)
+	if err != nil {
+		return err
+	}
+	for _, t := range db2.tables {
+		t.clock = db.clock
+	}
+	db.m.Lock()
+	defer db.m.Unlock()
+	db.tableNames, db.tables = db2.tableNames, db2.tables
+	return nil
+}
+
+// Watch polls the fixture file or table directory layout at path every
+// interval and reloads db with Reload when the size or modification time
+// of any of its files changes. Polling needs no platform specific file
+// notifications. If onReload is not nil, it is called with the result of
+// every reload, e.g. to log fixtures that failed to load. Mutations of db
+// since the last load are discarded by a reload unless db persists them
+// to path, see Persist.
+func (db *DB) Watch(path string, interval time.Duration, onReload func(error)) *Watcher {
+	w := &Watcher{
+		db:       db,
+		path:     path,
+		onReload: onReload,
+		stamp:    fixtureStamp(path),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run(db.Clock().NewTicker(interval))
+	return w
+}
+
+// Stop stops polling and waits for a running reload to finish.
+func (w *Watcher) Stop() {
+	close(w.stop)
+	<-w.done
+}
+
+func (w *Watcher) run(ticker Ticker) {
+	defer close(w.done)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C():
+			w.poll()
+		}
+	}
+}
+
+// poll reloads the DB if its fixture changed since the last poll. A
+// fixture that fails to load is reloaded after its next change.
+func (w *Watcher) poll() {
+	stamp := fixtureStamp(w.path)
+	if stamp == w.stamp {
+		return
+	}
+	w.stamp = stamp
+	err := w.db.Reload(w.path)
+	if w.onReload != nil {
+		w.onReload(err)
+	}
+}
+
+// fixtureStamp returns the names, sizes and modification times of all
+// files at path. Errors, such as a missing path, are part of the stamp so
+// that the fixture is reloaded once they are resolved.
+func fixtureStamp(path string) string {
+	sb := &strings.Builder{}
+	_ = filepath.Walk(path, func(fname string, fi os.FileInfo, err error) error {
+		switch {
+		case err != nil:
+			fmt.Fprintf(sb, "%s: %v\n", fname, err)
+		case !fi.IsDir():
+			fmt.Fprintf(sb, "%s %d %d\n", fname, fi.Size(), fi.ModTime().UnixNano())
+		}
+		return nil
+	})
+	return sb.String()
+}
@@ -0,0 +1,131 @@
+package dynamock
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	dir := writeExportFiles(t, map[string]string{
+		"db.yaml": "tables: [{name: t, schema: {primaryKey: {partitionKey: {name: id, type: string}}}, items: [{id: a}]}]",
+	})
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.yaml")
+	db := ReadTestdataDB(t, "db.json")
+	clock := NewManualClock(epoch)
+	db.SetClock(clock)
+
+	require.NoError(t, db.Reload(path))
+	require.Equal(t, []string{"t"}, db.tableNames)
+	require.Same(t, clock, db.tables["t"].clock)
+
+	require.NoError(t, writeFileAtomic(path, []byte("tables: [{name: u, schema: {}}]")))
+	err := db.Reload(path)
+	requireErrIs(t, err, ErrSchemaValidation)
+	require.Equal(t, []string{"t"}, db.tableNames)
+	require.Len(t, db.tables["t"].items, 1)
+}
+
+func TestWatch(t *testing.T) {
+	dir := copyTestdata(t, "db.json")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.json")
+	db, err := NewDBFromFile(path)
+	require.NoError(t, err)
+	reloaded := make(chan error, 10)
+	w := db.Watch(path, time.Millisecond, func(err error) { reloaded <- err })
+	defer w.Stop()
+
+	// requests keep being served while tables are swapped
+	stop := make(chan struct{})
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = putProduct(t, db, "9")
+			}
+		}
+	}()
+
+	fixture := `{"tables": [{"name": "t", "schema": {"primaryKey": {"partitionKey": {"name": "id", "type": "string"}}}, "items": [{"id": "a"}]}]}`
+	require.NoError(t, writeFileAtomic(path, []byte(fixture)))
+	require.NoError(t, <-reloaded)
+	table, err := db.table(strPtr("t"))
+	require.NoError(t, err)
+	require.Len(t, table.items, 1)
+	close(stop)
+	<-served
+
+	// invalid fixtures are reported and keep the previous tables
+	require.NoError(t, writeFileAtomic(path, []byte(`{"tables": [{"name": "t", "items": [{}]}]}`)))
+	requireErrIs(t, <-reloaded, ErrSchemaValidation)
+	require.NoError(t, os.Remove(path))
+	require.Error(t, <-reloaded)
+	_, err = db.table(strPtr("t"))
+	require.NoError(t, err)
+	require.NoError(t, writeFileAtomic(path, []byte(`{"tables": []}`)))
+	require.NoError(t, <-reloaded)
+	require.Empty(t, db.tableList())
+
+	w2 := db.Watch(path, time.Hour, nil)
+	require.NoError(t, writeFileAtomic(path, []byte(fixture)))
+	w2.poll()
+	w2.poll() // unchanged
+	w2.Stop()
+	_, err = db.table(strPtr("t"))
+	require.NoError(t, err)
+}
+
+func TestWatchClock(t *testing.T) {
+	dir := copyTestdata(t, "db.json")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.json")
+	db, err := NewDBFromFile(path)
+	require.NoError(t, err)
+	clock := NewManualClock(epoch)
+	db.SetClock(clock)
+	reloaded := make(chan error, 10)
+	w := db.Watch(path, time.Second, func(err error) { reloaded <- err })
+	defer w.Stop()
+	clock.BlockUntil(1)
+
+	require.NoError(t, writeFileAtomic(path, []byte(`{"tables": []}`)))
+	clock.Advance(999 * time.Millisecond)
+	require.NotNil(t, db.Table("product"))
+	clock.Advance(time.Millisecond)
+	require.NoError(t, <-reloaded)
+	require.Empty(t, db.tableList())
+}
+
+func TestWatchDir(t *testing.T) {
+	dir := writeExportFiles(t, map[string]string{
+		"product/table.json": `{"schema": {"primaryKey": {"partitionKey": {"name": "id", "type": "string"}}}}`,
+	})
+	defer os.RemoveAll(dir)
+	db, err := NewDBFromDir(dir)
+	require.NoError(t, err)
+	reloaded := make(chan error, 10)
+	w := db.Watch(dir, time.Millisecond, func(err error) { reloaded <- err })
+	defer w.Stop()
+
+	// move a complete item file into place so no poll sees a partial one
+	tmp, err := ioutil.TempFile("", "a.jsonl")
+	require.NoError(t, err)
+	_, err = tmp.WriteString(`{"id": "1"}`)
+	require.NoError(t, err)
+	require.NoError(t, tmp.Close())
+	require.NoError(t, os.Rename(tmp.Name(), filepath.Join(dir, "product", "a.jsonl")))
+	require.NoError(t, <-reloaded)
+	table, err := db.table(strPtr("product"))
+	require.NoError(t, err)
+	require.Len(t, table.items, 1)
+}
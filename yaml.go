@@ -0,0 +1,93 @@
+package dynamock
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"foxygo.at/s/errs"
+	"gopkg.in/yaml.v3"
+)
+
+// NewDBFromYAMLReader reads a YAML fixture with the same structure and
+// field names as the JSON fixture read by NewDBFromReader. Anchors,
+// aliases and merge keys (<<) can be used to share item templates.
+// Relative createTable paths are resolved against the current working
+// directory.
+func NewDBFromYAMLReader(r io.Reader) (*DB, error) {
+	jdb, err := decodeYAMLFixture(r)
+	if err != nil {
+		return nil, errs.Errorf("NewDBFromYAMLReader: %v", err)
+	}
+	return newDBFromJSON(jdb, "")
+}
+
+func decodeYAMLFixture(r io.Reader) (*JSONDB, error) {
+	jdb := &JSONDB{}
+	if err := decodeYAML(r, jdb); err != nil {
+		return nil, err
+	}
+	return jdb, nil
+}
+
+// decodeYAML decodes YAML into v by way of JSON, so that YAML fixtures
+// are decoded exactly like JSON fixtures.
+func decodeYAML(r io.Reader, v interface{}) error {
+	doc := yaml.Node{}
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return err
+	}
+	untagTimestamps(&doc)
+	var val interface{}
+	if err := doc.Decode(&val); err != nil {
+		return err
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// untagTimestamps keeps unquoted dates such as 2020-01-02 as strings, as
+// they would be in JSON, rather than decoding them to time.Time.
+func untagTimestamps(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!timestamp" && node.Style&yaml.TaggedStyle == 0 {
+		node.Tag = "!!str"
+	}
+	for _, n := range node.Content {
+		untagTimestamps(n)
+	}
+}
+
+// WriteYAMLSnap writes all tables as YAML fixture that can be read with
+// NewDBFromYAMLReader. Each table is written in the format it was loaded
+// from.
+func (db *DB) WriteYAMLSnap(w io.Writer) error {
+	_, err := w.Write(encodeYAML(db.snap("")))
+	return err
+}
+
+// encodeYAML encodes v by way of JSON, which keeps the field order and
+// names of JSON fixtures. Neither step can fail for fixture values.
+func encodeYAML(v interface{}) []byte {
+	b, _ := json.Marshal(v)
+	doc := yaml.Node{}
+	_ = yaml.Unmarshal(b, &doc)
+	blockStyle(&doc)
+	buf := &bytes.Buffer{}
+	e := yaml.NewEncoder(buf)
+	e.SetIndent(2)
+	_ = e.Encode(&doc)
+	_ = e.Close()
+	return buf.Bytes()
+}
+
+// blockStyle resets the flow and quoting styles of nodes decoded from
+// JSON. Strings that need quoting are still quoted by the encoder.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		blockStyle(n)
+	}
+}
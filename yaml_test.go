@@ -0,0 +1,114 @@
+package dynamock
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDBFromFileYAML(t *testing.T) {
+	db, err := NewDBFromFile(filepath.Join("testdata", "db.yaml"))
+	require.NoError(t, err)
+	sb := &bytes.Buffer{}
+	require.NoError(t, db.WriteSnap(sb))
+	require.JSONEq(t, string(ReadTestdataBytes(t, "db.json")), sb.String())
+
+	db, err = NewDBFromFile(filepath.Join("testdata", "db.json"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"product", "person", "path"}, db.tableNames)
+}
+
+func TestNewDBFromFileCreateTable(t *testing.T) {
+	db, err := NewDBFromFile(filepath.Join("testdata", "createtable.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"order"}, db.tableNames)
+	require.Equal(t, orderSchema().LSIs, db.tables["order"].schema.LSIs)
+}
+
+func TestNewDBFromYAMLReader(t *testing.T) {
+	r := strings.NewReader(`
+tables:
+  - name: event
+    schema: { primaryKey: { partitionKey: { name: id, type: string } } }
+    items:
+      - &event { id: a, day: 2020-01-02, kind: start }
+      - { <<: *event, id: b }
+`)
+	db, err := NewDBFromYAMLReader(r)
+	require.NoError(t, err)
+	items := db.tables["event"].items
+	require.Len(t, items, 2)
+	require.Equal(t, "2020-01-02", *items[1]["day"].S)
+	require.Equal(t, "start", *items[1]["kind"].S)
+}
+
+func TestWriteYAMLSnap(t *testing.T) {
+	for _, fname := range []string{"db.json", "sets.json", "typed.json", "binary.json"} {
+		fname := fname
+		t.Run(fname, func(t *testing.T) {
+			db := ReadTestdataDB(t, fname)
+			sb := &bytes.Buffer{}
+			require.NoError(t, db.WriteYAMLSnap(sb))
+			require.True(t, strings.HasPrefix(sb.String(), "tables:\n  - name: "))
+
+			db2, err := NewDBFromYAMLReader(sb)
+			require.NoError(t, err)
+			sb.Reset()
+			require.NoError(t, db2.WriteSnap(sb))
+			require.JSONEq(t, string(ReadTestdataBytes(t, fname)), sb.String())
+		})
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestWriteYAMLSnapErr(t *testing.T) {
+	db := ReadTestdataDB(t, "db.json")
+	require.Error(t, db.WriteYAMLSnap(errWriter{}))
+}
+
+func TestNewDBFromFileErr(t *testing.T) {
+	_, err := NewDBFromFile(filepath.Join("testdata", "db.txt"))
+	requireErrIs(t, err, ErrUnknownType)
+
+	_, err = NewDBFromFile(filepath.Join("testdata", "MISSING.yaml"))
+	require.Error(t, err)
+
+	dir := writeExportFiles(t, map[string]string{"db.json": "{", "db.yml": "tables: ["})
+	defer os.RemoveAll(dir)
+	_, err = NewDBFromFile(filepath.Join(dir, "db.json"))
+	require.Error(t, err)
+
+	_, err = NewDBFromFile(filepath.Join(dir, "db.yml"))
+	require.Error(t, err)
+}
+
+func TestNewDBFromYAMLReaderErr(t *testing.T) {
+	testCases := map[string]string{
+		"bad_yaml":   "tables: [",
+		"empty":      "",
+		"bad_scalar": "tables: !!int abc",
+		"int_keys":   "tables: [{1: a}]",
+		"nan":        "tables: [{name: .nan}]",
+		"structure":  "tables: 1",
+	}
+	for name, in := range testCases {
+		in := in
+		t.Run(name, func(t *testing.T) {
+			_, err := NewDBFromYAMLReader(strings.NewReader(in))
+			require.Error(t, err)
+		})
+	}
+	r := strings.NewReader("tables: [{name: t, schema: {primaryKey: {partitionKey: {name: id, type: bool}}}}]")
+	_, err := NewDBFromYAMLReader(r)
+	requireErrIs(t, err, ErrUnknownType)
+}